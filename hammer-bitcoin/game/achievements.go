@@ -0,0 +1,200 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// Achievement is a goal a player can reach besides the final rating. Once reached it stays
+// unlocked in the player's profile, whatever becomes of later games.
+type Achievement struct {
+	// ID names the achievement in the profile, and its name and description in the locales
+	ID string
+	// earned reports whether the game so far reaches the goal, given its ledger and the
+	// state at the end of the last year played
+	earned func(l Ledger, s GameState) bool
+}
+
+// Achievements lists every achievement, in the order they are shown
+var Achievements = []Achievement{
+	{ID: "no-starvation", earned: func(l Ledger, s GameState) bool {
+		return survived(s) && l.totalStarved() == 0
+	}},
+	{ID: "computer-baron", earned: func(l Ledger, s GameState) bool {
+		return survived(s) && s.Computers > 2000
+	}},
+	{ID: "crash-survivor", earned: func(l Ledger, s GameState) bool {
+		return l.crashesRecovered() >= 2
+	}},
+	{ID: "debt-free", earned: func(l Ledger, s GameState) bool {
+		return survived(s) && s.Debt == 0 && l.borrowed()
+	}},
+	{ID: "devoted-staff", earned: func(l Ledger, s GameState) bool {
+		return s.Morale >= 80
+	}},
+	{ID: "dollar-millionaire", earned: func(l Ledger, s GameState) bool {
+		return s.Fiat >= 1000000
+	}},
+	{ID: "superb", earned: func(l Ledger, s GameState) bool {
+		return survived(s) && FinalRating(s) == Superb
+	}},
+}
+
+// Name returns the name of the achievement, in the active locale
+func (a Achievement) Name() string {
+	return Text("achievement." + a.ID)
+}
+
+// Description returns what it takes to earn the achievement, in the active locale
+func (a Achievement) Description() string {
+	return Text("achievement." + a.ID + ".description")
+}
+
+// survived reports whether the term ran its course without the player being thrown out
+func survived(s GameState) bool {
+	return s.Finished() && !s.Evicted && !s.Bankrupt
+}
+
+// totalStarved returns how many employees starved over the years played
+func (l Ledger) totalStarved() int {
+	total := 0
+	for _, e := range l {
+		total = total + e.Starved
+	}
+	return total
+}
+
+// borrowed reports whether the player took out a loan in any of the years played
+func (l Ledger) borrowed() bool {
+	for _, e := range l {
+		if e.Decisions.Borrow > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// crashesRecovered counts the market crashes the corporation recovered from: the years with a
+// crash after which the standing climbed back to where it was before the crash
+func (l Ledger) crashesRecovered() int {
+	recovered := 0
+	for i, e := range l {
+		if !e.hadEvent("crash") {
+			continue
+		}
+		for _, later := range l[i+1:] {
+			if later.StandingAfter >= e.StandingBefore {
+				recovered++
+				break
+			}
+		}
+	}
+	return recovered
+}
+
+// hadEvent reports whether the event called name happened in the year
+func (e LedgerEntry) hadEvent(name string) bool {
+	for _, event := range e.Events {
+		if event == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Unlock is an achievement in a profile, and the game it was earned in
+type Unlock struct {
+	ID         string    `json:"id"`
+	Date       time.Time `json:"date"`
+	Seed       uint64    `json:"seed"`
+	Difficulty string    `json:"difficulty"`
+}
+
+// Profile is the achievements a player has unlocked, kept in a local JSON file
+type Profile struct {
+	path     string
+	Unlocked []Unlock `json:"unlocked"`
+}
+
+// DefaultProfileFile returns where the profile is kept unless told otherwise:
+// in the user's config directory, or the current directory if there is none
+func DefaultProfileFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "bitcoin-miner-profile.json"
+	}
+	return filepath.Join(dir, "hammer-bitcoin", "profile.json")
+}
+
+// LoadProfile reads the profile at path. A missing file is a profile with nothing unlocked.
+func LoadProfile(path string) (*Profile, error) {
+	p := &Profile{path: path}
+	err := readJSONFile(path, p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading profile: %w", err)
+	}
+	return p, nil
+}
+
+// Save writes the profile back to the file it was loaded from
+func (p *Profile) Save() error {
+	if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return fmt.Errorf("saving profile: %w", err)
+	}
+	if err := writeJSONFile(p.path, p); err != nil {
+		return fmt.Errorf("saving profile: %w", err)
+	}
+	return nil
+}
+
+// Has reports whether the achievement called id is unlocked
+func (p *Profile) Has(id string) bool {
+	_, ok := p.unlock(id)
+	return ok
+}
+
+// unlock returns the unlock of the achievement called id, if it is unlocked
+func (p *Profile) unlock(id string) (Unlock, bool) {
+	for _, u := range p.Unlocked {
+		if u.ID == id {
+			return u, true
+		}
+	}
+	return Unlock{}, false
+}
+
+// Check unlocks every achievement the game so far has earned that the profile does not
+// have yet, and returns them. The game was started with seed.
+func (p *Profile) Check(l Ledger, s GameState, seed uint64) []Achievement {
+	var earned []Achievement
+	for _, a := range Achievements {
+		if p.Has(a.ID) || !a.earned(l, s) {
+			continue
+		}
+		p.Unlocked = append(p.Unlocked, Unlock{ID: a.ID, Date: time.Now(), Seed: seed, Difficulty: s.Rules.difficulty()})
+		earned = append(earned, a)
+	}
+	return earned
+}
+
+// PrintAchievements writes every achievement as a table, with the date it was unlocked in the profile
+func PrintAchievements(w io.Writer, p *Profile) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, a := range Achievements {
+		unlocked := Text("achievement.locked")
+		if u, ok := p.unlock(a.ID); ok {
+			unlocked = u.Date.Format("2006-01-02")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", unlocked, a.Name(), a.Description())
+	}
+	tw.Flush()
+}
@@ -0,0 +1,82 @@
+package game
+
+// creditLimit returns the most the bank will lend the corporation in all: a share of what
+// its computers would sell for
+func (s GameState) creditLimit() int {
+	value := 0
+	for _, b := range s.Fleet {
+		value = value + b.Count*s.resaleValue(b)
+	}
+	return value * s.Rules.CreditLimit / 100
+}
+
+// Credit returns how much more the bank will lend the corporation this year
+func (s GameState) Credit() int {
+	return max(s.creditLimit()-s.Debt, 0)
+}
+
+// borrow takes out a loan of amount bitcoins. A negative amount repays that much of the debt.
+func (s *GameState) borrow(amount int) {
+	s.Cash = s.Cash + amount
+	s.Debt = s.Debt + amount
+}
+
+// interestDue returns the interest the debt accrues in a year, rounded up
+func (s GameState) interestDue() int {
+	return (s.Debt*s.Rules.InterestRate + 99) / 100
+}
+
+// settleDebt pays the bank at the end of the year: the year's interest, plus any debt above
+// the credit limit, which shrinks as the computers age. When the cash does not cover the
+// payment, the bank takes the dollars at the going rate, then seizes the oldest computers and
+// sells them to make up the difference; when there are not enough of them either, the
+// corporation is bankrupt.
+func (s *GameState) settleDebt(report *YearReport) {
+	if s.Debt <= 0 {
+		return
+	}
+	interest := s.interestDue()
+	calledIn := max(s.Debt-s.creditLimit(), 0)
+	due := interest + calledIn
+
+	if due > s.Cash {
+		bought := min(due-s.Cash, s.Fiat/s.ExchangeRate)
+		s.convert(-bought)
+		report.DollarsSeized = bought * s.ExchangeRate
+	}
+	if due > s.Cash {
+		seized, proceeds := s.computersToCover(due - s.Cash)
+		s.retire(seized)
+		s.Cash = s.Cash + proceeds
+		report.ComputersSeized = seized
+	}
+	if due > s.Cash {
+		// the bank takes all there is, and the rest of the debt and interest goes unpaid
+		s.Debt = s.Debt + interest - s.Cash
+		s.Cash = 0
+		s.Bankrupt = true
+		report.Bankrupt = true
+		return
+	}
+	s.Cash = s.Cash - due
+	s.Debt = s.Debt - calledIn
+	report.InterestPaid = interest
+	report.DebtCalledIn = calledIn
+}
+
+// computersToCover returns how many of the oldest computers the bank has to sell to raise
+// amount bitcoins, and what they fetch. If the whole fleet does not fetch that much, the
+// whole fleet is sold.
+func (s GameState) computersToCover(amount int) (int, int) {
+	seized, proceeds := 0, 0
+	for _, b := range s.oldestFirst() {
+		value := max(s.resaleValue(b), 1)
+		n := min((amount-proceeds+value-1)/value, b.Count)
+		seized = seized + n
+		proceeds = proceeds + n*value
+		if proceeds >= amount {
+			break
+		}
+	}
+	return seized, proceeds
+}
@@ -1,228 +1,467 @@
 package game
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"time"
 
 	"log"
-	"math/rand"
-	"os"
-	"os/exec"
-	"runtime"
-	"strconv"
+	"math/rand/v2"
 	"strings"
 
 	"github.com/eiannone/keyboard"
 	"github.com/fatih/color"
 )
 
-// OGH is how we address the player
-const OGH = "O Great Gill Bates"
-
-// default values
-var year = 1
-var employees = 100
-var cash = 2800
-var computers = 1000
-var computerPrice int
-var computersMaintained int
-var cashPaidToEmployees int
-var starved = 0
-var marketCrashVictims = 0
-var newEmployees = 5
-var cashMined = 3000
-var bitcoinGeneratedPerComputer = 3
-var amountStolenByHackers = 200
-
-// Play plays the game
-func Play() {
+// Config holds the options a game is started with
+type Config struct {
+	// Rules are the rules a new game is played by. The zero value means DefaultRules.
+	Rules Rules
+	// Seed seeds the random number generator. Zero picks a seed from the clock.
+	Seed uint64
+	// Replay, if set, supplies the rules, the seed and the player's answers of an earlier game.
+	Replay *Replay
+	// RecordFile, if set, is where a replay of the game is written once it ends.
+	RecordFile string
+	// Load, if set, is a saved game to resume instead of starting a new term.
+	Load *SavedGame
+	// PlayerName is the name the game is put on the leaderboard under.
+	PlayerName string
+	// ScoresFile, if set, is the leaderboard the finished game is added to. Replays are never added.
+	ScoresFile string
+	// ProfileFile, if set, is the profile the achievements earned in the game are unlocked in.
+	// Replays never unlock achievements.
+	ProfileFile string
+	// LedgerFile, if set, is where the year by year history of the game is exported once it
+	// ends, as JSON if the name ends in .json and as CSV otherwise. If it is not set the player
+	// is offered the export.
+	LedgerFile string
+	// TUI plays the game on a full-screen dashboard instead of question by question.
+	// Replays are always played back question by question.
+	TUI bool
+}
+
+// Play plays the game. It returns ErrEndOfInput if the answers ran out before the game was over.
+func Play(cfg Config) error {
+	rules := cfg.Rules
+	if rules.Name == "" {
+		rules = DefaultRules
+	}
+	seed := cfg.Seed
+	if cfg.Replay != nil {
+		rules = cfg.Replay.Rules
+		seed = cfg.Replay.Seed
+	}
+	if cfg.Load != nil {
+		rules = cfg.Load.State.Rules
+		seed = cfg.Load.Seed
+	}
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+
 	// seed the random number generator
-	// rand.Seed(time.Now().UnixNano())
-	rand.New(rand.NewSource(time.Now().UnixNano()))
-	// write greeting
-	printIntroductoryParagraph()
-
-	stillInOffice := true
-
-	// play for 10 years, or until kicked out
-	for stillInOffice && year <= 10 {
-		computerPrice = updateComputerPrice()
-		printSummary()
-		buyComputers()
-		sellComputers()
-		payEmployees()
-		maintainComputers()
-		marketCrashVictims = checkForCrash()
-		employees = employees - marketCrashVictims
-
-		if countStarvedEmployees() >= 45 {
-			stillInOffice = false
+	src := newSource(seed)
+	rng := rand.New(src)
+	in := newConsole(cfg.Replay, rules, seed)
+	if cfg.ProfileFile != "" && cfg.Replay == nil {
+		profile, err := LoadProfile(cfg.ProfileFile)
+		if err != nil {
+			log.Println(err)
 		}
+		in.profile = profile
+	}
 
-		newEmployees = countNewHires()
-		employees = employees + newEmployees
+	var state GameState
+	if cfg.Load != nil {
+		// pick up where the saved game left off, replaying the answers already given this year
+		if err := src.UnmarshalBinary(cfg.Load.RNG); err != nil {
+			return err
+		}
+		state = cfg.Load.State
+		in.recording.Answers = append(in.recording.Answers, cfg.Load.Answers...)
+		in.playback = cfg.Load.YearAnswers
+		in.ledger = cfg.Load.Ledger
+		clearScreen()
+		color.Yellow("%s\n", Text("welcomeBack", title(), state.Year))
+	} else {
+		// write greeting
+		printIntroductoryParagraph(rules)
+		state = NewGame(rules, rng)
+	}
+
+	in.save = func(path string) error {
+		rngState, err := src.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		saved := SavedGame{
+			Version:     saveVersion,
+			Seed:        seed,
+			RNG:         rngState,
+			State:       state,
+			Answers:     in.answersBeforeYear(),
+			YearAnswers: in.yearAnswers,
+			Ledger:      in.ledger,
+		}
+		return saved.Save(path)
+	}
+
+	// play for the whole term, or until kicked out
+	if cfg.TUI && cfg.Replay == nil && interactive {
+		finished, err := playDashboard(in, &state, rng)
+		if !finished || err != nil {
+			return err
+		}
+	} else if err := playConsole(in, &state, rng); err != nil {
+		return err
+	}
 
-		cash = cash + mineBitCoin(computersMaintained)
+	printFinalScore(state)
+	printAchievements(in.takeEarned())
 
-		checkForHackers()
-		computerPrice = updateComputerPrice()
-		year = year + 1
+	if cfg.ScoresFile != "" && cfg.Replay == nil {
+		recordScore(cfg.ScoresFile, NewScore(cfg.PlayerName, seed, state))
 	}
 
-	printFinalScore()
+	fmt.Println("")
+	WriteReport(color.Output, in.ledger, state)
+	exportLedger(in, cfg)
 
+	if cfg.RecordFile != "" {
+		if err := in.recording.Save(cfg.RecordFile); err != nil {
+			log.Println(err)
+		} else {
+			fmt.Println("")
+			fmt.Println(Text("replaySaved", seed, cfg.RecordFile))
+		}
+	}
+	return nil
 }
 
-// printIntroductoryParagraph prints the intro paragraph
-func printIntroductoryParagraph() {
-	clearScreen()
-	color.Yellow(`BITCOIN MINER
--------------
-Congratulations! You are the newest CEO of Make Me Rich, Inc, elected for a ten year term. Your
-duties are to dispense living expenses for employees, direct mining of bitcoin, and buy and sell
-computers as needed to support the corporation.
+// playConsole plays the rest of the term question by question
+func playConsole(in *console, state *GameState, rng *rand.Rand) error {
+	for !state.Finished() {
+		in.startYear()
+		printSummary(state.Summary())
+		printAchievements(in.takeEarned())
+		decisions, err := askForDecisions(in, *state)
+		if err != nil {
+			return err
+		}
 
-Watch out for hackers and market crashes!
+		before := *state
+		var report YearReport
+		*state, report = Step(*state, decisions, rng)
+		printYearEvents(report)
+		in.endYear(before, decisions, *state, report)
+	}
+	return nil
+}
 
-Cash is the general currency, measured in bitcoins.
+// exportLedger exports the ledger to the file named in the config, or offers the player
+// the export if none is named. A game whose answers are not typed is not offered the export,
+// so that a leftover answer is not taken for a file name.
+func exportLedger(in *console, cfg Config) {
+	path := cfg.LedgerFile
+	if path == "" && cfg.Replay == nil && interactive {
+		fmt.Println("")
+		fmt.Println(Text("exportPrompt"))
+		fmt.Print("-> ")
+		answer, _ := readLine()
+		path = strings.TrimSpace(answer)
+	}
+	if path == "" {
+		return
+	}
 
-The following will help you in your decisions:
+	if err := in.ledger.Export(path); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(Text("exported", path))
+}
 
-	* Each employee needs at least 20 bitcoins converted to cash per year to survive
-	* Each employee can maintain at most 10 computers
-	* It takes 2 bitcoins to pay for electricity to mine bitcoin on a computer
-	* The market price for computers fluctuates yearly
+// recordScore adds a finished game to the leaderboard and tells the player where it ranks
+func recordScore(path string, score Score) {
+	leaderboard, err := LoadLeaderboard(path)
+	if err != nil {
+		log.Println(err)
+		return
+	}
 
-Lead the team wisely and you will be showered with appreciation at the end of your term.
+	rank, total := leaderboard.Add(score)
+	if err := leaderboard.Save(); err != nil {
+		log.Println(err)
+		return
+	}
 
-Do it poorly and you will be terminated!
+	fmt.Println("")
+	fmt.Println(Text("leaderboardPlace", score.Name, rank, total, score.Difficulty))
+}
 
-`)
+// printIntroductoryParagraph prints the intro paragraph
+func printIntroductoryParagraph(rules Rules) {
+	clearScreen()
+	color.Yellow("%s", Text("intro", rules.TermYears, rules.LivingCost, rules.ComputersPerEmployee))
 }
 
+// minComputerPrice and maxComputerPrice bound the price of a computer in a year
+const (
+	minComputerPrice = 17
+	maxComputerPrice = 26
+)
+
 // updateComputerPrice Randomly sets the new price of computers.
 // returns the new price of a computer as an int.
-// The price fluctuates from 17 to 26 bitcoin per computer.
-func updateComputerPrice() int {
-	return rand.Intn(10) + 17
+// The price fluctuates from minComputerPrice to maxComputerPrice bitcoin per computer.
+func updateComputerPrice(rng *rand.Rand) int {
+	return rng.IntN(maxComputerPrice-minComputerPrice+1) + minComputerPrice
 }
 
 // printSummary prints the year-end summary
-func printSummary() {
-	fmt.Printf("%s!", OGH)
-	fmt.Println("")
-	fmt.Println(fmt.Sprintf("You are in year %d of your rule.", year))
-
-	if marketCrashVictims > 0 {
-		color.Red("A terrible market crash wiped out %d of your team.", marketCrashVictims)
+func printSummary(s Summary) {
+	fmt.Println(Text("summary.greeting", title()))
+	fmt.Println(Text("summary.year", s.Year))
+
+	for _, event := range s.Events {
+		// the hackers are reported along with the cash below
+		if event.Name != "hackers" {
+			color.Red("%s", event.Message)
+		}
 	}
 
-	fmt.Println(fmt.Sprintf("In the previous year, %d of your team starved to death.", starved))
-	fmt.Println(fmt.Sprintf("In the previous year, %d employee(s) got employed by the corporation.", newEmployees))
-	fmt.Println(fmt.Sprintf("The employee head count is now %d.", employees))
-	fmt.Println(fmt.Sprintf("We mined %d bitcoins at %d bitcoins per computer.", cashMined, bitcoinGeneratedPerComputer))
+	fmt.Println(Plural("summary.starved", s.Starved, s.Starved))
+	fmt.Println(Plural("summary.hired", s.NewEmployees, s.NewEmployees))
+	fmt.Println(Text("summary.headCount", s.Employees))
+	fmt.Println(Text("summary.morale", mood(s.Morale), s.Morale))
+	fmt.Println(Text("summary.skill", s.Skill, skillBonus(s.Skill)))
+	fmt.Println(Text("summary.mined", s.CashMined, s.BitcoinGeneratedPerComputer))
 
-	if amountStolenByHackers > 0 {
-		color.Red("*** Hackers stole %d bitcoins, leaving %d bitcoins in your online wallet.", amountStolenByHackers, cash)
+	if s.AmountStolenByHackers > 0 {
+		color.Red("%s", Text("summary.hacked", s.AmountStolenByHackers, s.Cash))
 	} else {
-		fmt.Println(fmt.Sprintf("We have %d bitcoins of cash in storage.", cash))
+		fmt.Println(Text("summary.cash", s.Cash))
 	}
 
-	fmt.Println(fmt.Sprintf("The corporation owns %d computers for mining.", computers))
-	fmt.Println(fmt.Sprintf("Computers currently cost %d bitcoins each.", computerPrice))
+	fmt.Println(Text("summary.bank", s.Fiat, s.ExchangeRate))
+	fmt.Println(Text("summary.computers", s.Computers))
+	for _, h := range s.Fleet {
+		fmt.Println(Text("summary.holding", h.Count, generationName(h.Generation), h.Value))
+	}
+	fmt.Println(Text("summary.price", s.ComputerPrice))
+	if s.Debt > 0 {
+		fmt.Println(Text("summary.loan", s.Debt, s.InterestRate))
+	}
+	fmt.Println(Text("summary.credit", s.Credit))
 	fmt.Println("")
 }
 
+// printAchievements prints the achievements the player has just unlocked
+func printAchievements(earned []Achievement) {
+	for _, a := range earned {
+		color.Yellow("%s", Text("achievement.unlocked", a.Name(), a.Description()))
+	}
+	if len(earned) > 0 {
+		fmt.Println("")
+	}
+}
+
+// printCatalog prints the generations of computers on sale this year
+func printCatalog(catalog []Offer) {
+	fmt.Println(Text("catalog.title"))
+	for _, o := range catalog {
+		fmt.Println(Text("catalog.offer", o.Number, generationName(o.Generation), o.Price, o.Yield, o.Power, o.FailureRate))
+	}
+}
+
+// askForDecisions asks the player for this year's decisions. Each answer is checked against
+// what the corporation will have left after the answers before it.
+// It returns ErrEndOfInput if the input ends before they are all decided.
+func askForDecisions(in *console, s GameState) (Decisions, error) {
+	var d Decisions
+	var err error
+	if d.Borrow, err = takeLoan(in, &s); err != nil {
+		return d, err
+	}
+	if d.BitcoinToSell, err = convertBitcoin(in, &s); err != nil {
+		return d, err
+	}
+	if d.Generation, err = chooseGeneration(in, &s); err != nil {
+		return d, err
+	}
+	if d.ComputersToBuy, err = buyComputers(in, &s); err != nil {
+		return d, err
+	}
+	if d.ComputersToSell, err = sellComputers(in, &s); err != nil {
+		return d, err
+	}
+	if d.CashPaidToEmployees, err = payEmployees(in, &s); err != nil {
+		return d, err
+	}
+	d.MaintenanceAmount, err = maintainComputers(in, s)
+	return d, err
+}
+
+// askUntilValid asks question until the answer passes validate, jesting at every answer that doesn't
+func askUntilValid(in *console, question string, validate func(int) error) (int, error) {
+	for {
+		answer, err := in.getNumber(question)
+		if err != nil {
+			return 0, err
+		}
+		err = validate(answer)
+		if err == nil {
+			return answer, nil
+		}
+		jest(err)
+	}
+}
+
+// takeLoan allows the player to borrow from the bank up to the credit limit, or to repay the
+// debt by entering a negative amount
+func takeLoan(in *console, s *GameState) (int, error) {
+	question := Text("ask.borrow", s.Credit(), s.Rules.InterestRate)
+	borrow, err := askUntilValid(in, question, func(n int) error { return validateLoan(*s, n) })
+	if err != nil {
+		return 0, err
+	}
+	s.borrow(borrow)
+
+	if borrow != 0 {
+		fmt.Println(Text("told.borrow", title(), s.Debt, s.Cash))
+	}
+	return borrow, nil
+}
+
+// convertBitcoin allows the player to sell bitcoin for dollars, or to buy bitcoin with
+// dollars by entering a negative amount
+func convertBitcoin(in *console, s *GameState) (int, error) {
+	question := Text("ask.convert", s.ExchangeRate)
+	bitcoinToSell, err := askUntilValid(in, question, func(n int) error { return validateConvert(*s, n) })
+	if err != nil {
+		return 0, err
+	}
+	s.convert(bitcoinToSell)
+
+	fmt.Println(Text("told.convert", title(), s.Cash, s.Fiat))
+	return bitcoinToSell, nil
+}
+
+// chooseGeneration shows the player this year's catalog and asks which generation of
+// computers to buy, returning its name
+func chooseGeneration(in *console, s *GameState) (string, error) {
+	catalog := s.Catalog()
+	printCatalog(catalog)
+	number, err := askUntilValid(in, Text("ask.generation", len(catalog)), func(n int) error { return validateOffer(*s, n) })
+	if err != nil {
+		return "", err
+	}
+	generation := catalog[number-1].Generation
+	s.choose(generation)
+	return generation, nil
+}
+
 // buyComputers Allows the player to buy computers. If a valid amount is entered, the available cash is reduced
 // accordingly
-func buyComputers() {
-	question := "How many computers will you buy?"
-	computersToBuy := getNumber(question)
-	cost := computerPrice * computersToBuy
-	for cost > cash {
-		jest(fmt.Sprintf("We have but %d bitcoins of cash, not %d!", cash, cost))
-		computersToBuy := getNumber(question)
-		cost = computerPrice * computersToBuy
-	}
-	cash = cash - cost
-	computers = computers + computersToBuy
-
-	fmt.Println(fmt.Sprintf("%s, you now have %d computers", OGH, computers))
-	fmt.Println(fmt.Sprintf("and %d bitcoins of cash.", cash))
+func buyComputers(in *console, s *GameState) (int, error) {
+	g, _ := s.generation(s.buying)
+	question := Text("ask.buy", generationName(g.Name), s.priceOf(g))
+	computersToBuy, err := askUntilValid(in, question, func(n int) error { return validateBuy(*s, n) })
+	if err != nil {
+		return 0, err
+	}
+	s.buy(computersToBuy)
+
+	fmt.Println(Text("told.computers", title(), s.Computers, s.Cash))
+	return computersToBuy, nil
 }
 
 // sellComputers allows the player to sell computers, if any are on hand. Available
 // cash will be increased by the value of the computers sold
-func sellComputers() {
-	question := "How many computers will you sell?"
-	computersToSell := getNumber(question)
-
-	for computersToSell > computers {
-		jest(fmt.Sprintf("The corporation only has %d computers!", computers))
-		computersToSell = getNumber(question)
+func sellComputers(in *console, s *GameState) (int, error) {
+	question := Text("ask.sell")
+	computersToSell, err := askUntilValid(in, question, func(n int) error { return validateSell(*s, n) })
+	if err != nil {
+		return 0, err
 	}
-	computers = computers - computersToSell
-	cash = cash + computerPrice*computersToSell
+	s.sell(computersToSell)
 
-	fmt.Println(fmt.Sprintf("%s, you now have %d computers", OGH, computers))
-	fmt.Println(fmt.Sprintf("and %d bitcoins of cash.", cash))
+	fmt.Println(Text("told.computers", title(), s.Computers, s.Cash))
+	return computersToSell, nil
 }
 
 // payEmployees allows the player to decide how much cash to use to feed people. If a valid
 // amount is entered, the available cash is reduced accordingly
-func payEmployees() {
-	question := "How much bitcoin will you distribute to the employees?"
-	cashPaidToEmployees = getNumber(question)
-
-	for cashPaidToEmployees > cash {
-		jest(fmt.Sprintf("We have but %d bitcoins!", cash))
-		cashPaidToEmployees = getNumber(question)
+func payEmployees(in *console, s *GameState) (int, error) {
+	question := Text("ask.pay")
+	cashPaidToEmployees, err := askUntilValid(in, question, func(n int) error { return validatePay(*s, n) })
+	if err != nil {
+		return 0, err
 	}
-	cash = cash - cashPaidToEmployees
+	s.pay(cashPaidToEmployees)
 
-	fmt.Println(fmt.Sprintf("%s, %d bitcoins remain.", OGH, cash))
+	fmt.Println(Text("told.pay", title(), s.Cash))
+	return cashPaidToEmployees, nil
 }
 
 // maintainComputers allows the user to choose how much to spend on maintenance
-func maintainComputers() {
-	question := "How many bitcoins will you allocate for maintenance?"
-	maintenanceAmount := 0
-	haveGoodAnswer := false
-
-	for !haveGoodAnswer {
-		maintenanceAmount = getNumber(question)
-		if maintenanceAmount > cash {
-			jest(fmt.Sprintf("We have but %d bitcoins left!", cash))
-		} else if maintenanceAmount > 2*computers {
-			jest(fmt.Sprintf("We have but %d computers available for mining!", computers))
-		} else if maintenanceAmount > 20*employees {
-			jest(fmt.Sprintf("We have but %d people to maintain the computers!", employees))
-		} else {
-			haveGoodAnswer = true
-		}
+func maintainComputers(in *console, s GameState) (int, error) {
+	question := Text("ask.maintain")
+	maintenanceAmount, err := askUntilValid(in, question, func(n int) error { return validateMaintenance(s, n) })
+	if err != nil {
+		return 0, err
 	}
-	computersMaintained = maintenanceAmount / 2
 
-	fmt.Println(fmt.Sprintf("%s, we now have %d bitcoins in storage.", OGH, cash))
+	fmt.Println(Text("told.maintain", title(), s.Cash))
+	return maintenanceAmount, nil
 }
 
-// checkForCrash checks for market crash, and counts the victims
-func checkForCrash() int {
-	victims := 0
+// printYearEvents prints what happened once the year's decisions were carried out
+func printYearEvents(report YearReport) {
+	for _, event := range report.Events {
+		color.Red("*** %s ***", event.Message)
+	}
+
+	if report.Starved == 0 {
+		fmt.Println(Text("year.wellFed"))
+	} else {
+		color.Red("%s", Plural("year.starved", report.Starved, report.Starved))
+	}
+
+	if report.ComputersFailed > 0 {
+		color.Red("%s", Plural("year.failed", report.ComputersFailed, report.ComputersFailed))
+	}
+
+	switch {
+	case report.MoraleChange > 0:
+		fmt.Println(Text("year.moraleUp", report.MoraleChange))
+	case report.MoraleChange < 0:
+		color.Red("%s", Text("year.moraleDown", -report.MoraleChange))
+	}
 
-	diceRoll := rand.Intn(99) + 1
-	if diceRoll <= 15 {
-		color.Red("*** A terrible market crash wipes out half of the corporation's employees! ***")
-		victims = employees / 2
+	if report.InterestPaid > 0 {
+		fmt.Println(Text("year.interest", report.InterestPaid))
+	}
+	if report.DebtCalledIn > 0 {
+		color.Red("%s", Text("year.calledIn", report.DebtCalledIn))
+	}
+	if report.DollarsSeized > 0 {
+		color.Red("%s", Text("year.dollarsSeized", report.DollarsSeized))
+	}
+	if report.ComputersSeized > 0 {
+		color.Red("%s", Plural("year.seized", report.ComputersSeized, report.ComputersSeized))
+	}
+	if report.Bankrupt {
+		color.Red("%s", Text("year.bankrupt"))
 	}
-	return victims
 }
 
-// countNewHires counts how many new employees joined the company
-func countNewHires() int {
+// countNewHires counts how many new employees joined the company. No one joins a company
+// where people starved, or one with no employees left to recommend it.
+func countNewHires(starved, computers, cash, employees int) int {
 	var newEmployees int
-	if starved > 0 {
+	if starved > 0 || employees == 0 {
 		newEmployees = 0
 	} else {
 		newEmployees = (20*computers+cash)/(100*employees) + 1
@@ -230,145 +469,128 @@ func countNewHires() int {
 	return newEmployees
 }
 
-// checkForHackers checks if hackers get into the system, and determines how much they stole.
-func checkForHackers() {
-	diceRoll := rand.Intn(99) + 1
-	if diceRoll < 40 {
-		percentHacked := 10 + rand.Intn(21)
-		color.Red("*** Hackers steal %d percent of your bitcoins! ***", percentHacked)
-		amountStolenByHackers = (percentHacked * cash) / 100
-		cash = cash - amountStolenByHackers
-	} else {
-		amountStolenByHackers = 0
-	}
+// mineBitCoin collects the new cash mined by the maintained computers, each mining
+// according to its generation.
+// returns the bitcoin generated per computer and the total mined
+func mineBitCoin(rng *rand.Rand, s GameState, maintained int) (int, int) {
+	rules := s.Rules
+	bitcoinGeneratedPerComputer := rules.YieldMin + rng.IntN(rules.YieldMax-rules.YieldMin+1)
+	return bitcoinGeneratedPerComputer, bitcoinGeneratedPerComputer * s.hashPower(maintained) / 100
 }
 
-// mineBitCoin collects the new cash mined
-func mineBitCoin(computers int) int {
-	bitcoinGeneratedPerComputer = rand.Intn(6) + 1
-	cashMined = bitcoinGeneratedPerComputer * computers
-	return cashMined
-}
-
-// countStarvedEmployees counts how many people starved, and removes them from the employees
-func countStarvedEmployees() int {
-	employeesPaid := cashPaidToEmployees / 20
-	percentStarved := 0
+// countStarvedEmployees counts how many people starved out of the employees that were paid
+// livingCost each. returns the number starved and the percentage of the head count they made up
+func countStarvedEmployees(employees, cashPaidToEmployees, livingCost int) (int, int) {
+	employeesPaid := cashPaidToEmployees / livingCost
 
 	if employeesPaid >= employees {
-		starved = 0
-		fmt.Println("The corporation's employees are well fed and happy.")
-	} else {
-		starved = employees - employeesPaid
-		color.Red("%d employees starved to death.", starved)
-		percentStarved = (100 * starved) / employees
-		employees = employees - starved
+		return 0, 0
 	}
-	return percentStarved
+	starved := employees - employeesPaid
+	return starved, (100 * starved) / employees
 }
 
 // printFinalScore prints out the final score
-func printFinalScore() {
+func printFinalScore(s GameState) {
 	clearScreen()
 
-	if starved >= (45*employees)/100 {
-		color.Red(`O Once-Great %s,
-%d of your team starved during the last year of your incompetent reign!
-The few who remain hacked your bank account and changed your password, effectively evicting you!
-
-Your final rating: TERRIBLE.`, OGH, starved)
-		return
-	}
-
-	computerScore := computers
-
-	if 20*employees < computerScore {
-		computerScore = 20 * employees
+	score := NewFinalScore(s)
+	switch score.Rating {
+	case Bankrupt, Terrible:
+		color.Red("%s", score.Message)
+	case Adequate:
+		color.Cyan("%s", score.Message)
+	case Good:
+		color.Yellow("%s", score.Message)
+	case Superb:
+		color.White("%s", score.Message)
 	}
 
-	if computerScore < 600 {
-		color.Cyan(`Congratulations, %s.
-You have ruled wisely,  but not well. You have led your people through ten difficult
-years, but your corporation assets have shrunk to a mere %d computers.
-
-Your final rating: ADEQUATE`, OGH, computers)
-	} else if computerScore < 800 {
-		color.Yellow(`Congratulations %s,
-You  have ruled wisely, and shown the online world that it's possible to make money in cryptocurrency.
-
-Your final rating: GOOD.`, OGH)
-	} else {
-		color.White(`Congratulations %s,
-you  have ruled wisely and well, and expanded your holdings while keeping your team happy.
-Altogether, a most impressive job!
-
-Your final rating: SUPERB.`, OGH)
+	fmt.Println("")
+	fmt.Println(Text("final.worth", score.Cash, score.Fiat, score.NetWorth))
+	if score.Debt > 0 {
+		fmt.Println(Text("final.debt", score.Debt))
 	}
+	fmt.Println(Text("final.staff", score.Employees, mood(score.Morale), score.Morale, score.Skill))
 }
 
-// GetYesOrNo allows the player to try again, or quit
+// GetYesOrNo allows the player to try again, or quit. When the answers are not typed at the
+// keyboard, the answer is read from the next line of the input, and only an answer starting
+// with y is a yes, so that a leftover answer does not start another game.
 func GetYesOrNo(q string) bool {
-	err := keyboard.Open()
-	if err != nil {
-		log.Fatal(err)
+	fmt.Println(q)
+	if !interactive {
+		line, err := readLine()
+		answer := strings.TrimSpace(line)
+		return err == nil && (strings.HasPrefix(answer, "y") || strings.HasPrefix(answer, "Y"))
 	}
 
-	defer func() {
-		_ = keyboard.Close()
-	}()
+	char, _, err := keyboard.GetSingleKey()
+	if err != nil {
+		// fall back to reading a line if the keyboard cannot be put in raw mode
+		return answeredYes(readLine())
+	}
+	return char != 'n' && char != 'N'
+}
 
-	for {
-		fmt.Println(q)
-		char, _, err := keyboard.GetSingleKey()
-		if err != nil {
-			log.Fatal(err)
-		}
-		if char == 'n' || char == 'N' {
-			return false
-		}
-		return true
+// answeredYes reports whether line read from the input is anything but a no
+func answeredYes(line string, err error) bool {
+	if err != nil {
+		return false
 	}
+	answer := strings.TrimSpace(line)
+	return !strings.HasPrefix(answer, "n") && !strings.HasPrefix(answer, "N")
 }
 
-// clearScreen clears the screen
+// clearScreen clears the screen and moves the cursor to the top left corner. Nothing is
+// written when the output is not a terminal, to keep escape codes out of logs and pipes.
 func clearScreen() {
-	if strings.Contains(runtime.GOOS, "windows") {
-		// windows
-		cmd := exec.Command("cmd", "/c", "cls")
-		cmd.Stdout = os.Stdout
-		cmd.Run()
-	} else {
-		// linux or mac
-		cmd := exec.Command("clear")
-		cmd.Stdout = os.Stdout
-		cmd.Run()
+	if !terminalOutput {
+		return
 	}
+	fmt.Fprint(color.Output, ansiClear)
 }
 
-// getNumber prints question q and asks for a number, then returns it
-// as an int
-func getNumber(q string) int {
-	reader := bufio.NewReader(os.Stdin)
+// jest tells player that a request cannot be fulfilled, and why
+func jest(err error) {
+	fmt.Println("")
+	color.Magenta("%s", Text("jest.dreaming", title()))
+	fmt.Println(jestMessage(err))
+}
 
-	for {
-		fmt.Println(q)
-		fmt.Print("-> ")
-		userInput, _ := reader.ReadString('\n')
-		userInput = strings.Replace(userInput, "\r\n", "", -1)
-		userInput = strings.Replace(userInput, "\n", "", -1)
-		num, err := strconv.Atoi(userInput)
-		if err != nil {
-			fmt.Println("Please enter a whole number!")
-			continue
-		} else {
-			return num
-		}
+// jestMessage explains a turned down decision the way the game talks to the player
+func jestMessage(err error) string {
+	if errors.Is(err, errNotANumber) {
+		return Text("console.notANumber")
+	}
+	var de *DecisionError
+	if !errors.As(err, &de) {
+		return err.Error()
 	}
-}
 
-// jest tells player that a request cannot be fulfilled
-func jest(msg string) {
-	fmt.Println("")
-	color.Magenta("%s, you are dreaming!", OGH)
-	fmt.Println(msg)
+	switch {
+	case errors.Is(de, ErrNegativeAmount):
+		return Text("jest.negative." + de.Decision)
+	case errors.Is(de, ErrInsufficientFiat):
+		return Text("jest.fiat", de.Available, de.Amount)
+	case errors.Is(de, ErrInsufficientCash) && de.Decision == DecisionBuy:
+		return Text("jest.cashBuy", de.Available, de.Amount)
+	case errors.Is(de, ErrInsufficientCash) && (de.Decision == DecisionPay || de.Decision == DecisionConvert || de.Decision == DecisionLoan):
+		return Text("jest.cash", de.Available)
+	case errors.Is(de, ErrInsufficientCash):
+		return Text("jest.cashLeft", de.Available)
+	case errors.Is(de, ErrNotEnoughComputers) && de.Decision == DecisionSell:
+		return Text("jest.computersSell", de.Available)
+	case errors.Is(de, ErrNotEnoughComputers):
+		return Text("jest.computers", de.Available)
+	case errors.Is(de, ErrNotEnoughStaff):
+		return Plural("jest.staff", de.Available, de.Available)
+	case errors.Is(de, ErrOverCreditLimit):
+		return Text("jest.credit", de.Available)
+	case errors.Is(de, ErrNotOwed):
+		return Text("jest.owed", de.Available)
+	case errors.Is(de, ErrNotOnSale):
+		return Plural("jest.notOnSale", de.Available, de.Available)
+	}
+	return de.Error()
 }
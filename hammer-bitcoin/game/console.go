@@ -0,0 +1,139 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// console reads the player's answers from the input. The answers of a replay are played back
+// first, and every answer is recorded so that the game can be replayed later.
+// Typing "save" at any prompt calls save, so the game can be resumed from that prompt.
+type console struct {
+	playback    []int
+	recording   *Replay
+	yearAnswers []int
+	save        func(path string) error
+	// ledger is the history of the years played so far
+	ledger Ledger
+	// profile, if set, is where the achievements earned during the game are unlocked
+	profile *Profile
+	// earned are the achievements unlocked during the game that the player has not been shown yet
+	earned []Achievement
+}
+
+// newConsole returns a console that plays back replay, if there is one, and records a
+// replay of a game played by rules and started with seed
+func newConsole(replay *Replay, rules Rules, seed uint64) *console {
+	c := &console{
+		recording: NewReplay(rules, seed),
+	}
+	if replay != nil {
+		c.playback = replay.Answers
+	}
+	return c
+}
+
+// getNumber prints question q and asks for a number, then returns it
+// as an int. It returns ErrEndOfInput if the input ends first.
+func (c *console) getNumber(q string) (int, error) {
+	if len(c.playback) > 0 {
+		num := c.playback[0]
+		c.playback = c.playback[1:]
+		fmt.Println(q)
+		fmt.Printf("-> %d\n", num)
+		c.record(num)
+		return num, nil
+	}
+
+	for {
+		fmt.Println(q)
+		fmt.Print("-> ")
+		userInput, err := readLine()
+		if err != nil {
+			return 0, err
+		}
+
+		if command := strings.Fields(userInput); len(command) > 0 && command[0] == "save" && c.save != nil {
+			c.saveTo(command[1:])
+			continue
+		}
+
+		num, err := strconv.Atoi(userInput)
+		if err != nil {
+			fmt.Println(Text("console.notANumber"))
+			continue
+		} else {
+			c.record(num)
+			return num, nil
+		}
+	}
+}
+
+// pause prints q and waits for the player to press Enter
+func (c *console) pause(q string) {
+	fmt.Println(q)
+	_, _ = readLine()
+}
+
+// endYear records a year that took the corporation from before to after in the ledger
+// and in the replay
+func (c *console) endYear(before GameState, d Decisions, after GameState, r YearReport) {
+	c.ledger = append(c.ledger, NewLedgerEntry(before, d, after, r))
+	c.recording.Events = append(c.recording.Events, r.Events...)
+	c.checkAchievements(after)
+}
+
+// checkAchievements unlocks the achievements the game has earned by the end of a year that
+// took the corporation to s, and keeps them to be shown to the player
+func (c *console) checkAchievements(s GameState) {
+	if c.profile == nil {
+		return
+	}
+	earned := c.profile.Check(c.ledger, s, c.recording.Seed)
+	if len(earned) == 0 {
+		return
+	}
+	c.earned = append(c.earned, earned...)
+	if err := c.profile.Save(); err != nil {
+		log.Println(err)
+	}
+}
+
+// takeEarned returns the achievements the player has not been shown yet, and marks them shown
+func (c *console) takeEarned() []Achievement {
+	earned := c.earned
+	c.earned = nil
+	return earned
+}
+
+// startYear marks the start of a new year, so that a save only has to replay the answers given since
+func (c *console) startYear() {
+	c.yearAnswers = nil
+}
+
+// answersBeforeYear returns the answers given in the years before this one
+func (c *console) answersBeforeYear() []int {
+	return c.recording.Answers[:len(c.recording.Answers)-len(c.yearAnswers)]
+}
+
+// record keeps an answer for the replay and for saving the game
+func (c *console) record(num int) {
+	c.recording.Answers = append(c.recording.Answers, num)
+	c.yearAnswers = append(c.yearAnswers, num)
+}
+
+// saveTo saves the game to the file named in args, or to DefaultSaveFile
+func (c *console) saveTo(args []string) {
+	path := DefaultSaveFile
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	if err := c.save(path); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(Text("console.saved", path))
+}
@@ -0,0 +1,254 @@
+package game
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+)
+
+// EventPhase is the point in the year at which an event is rolled
+type EventPhase int
+
+const (
+	// BeforeStarvation events happen once the decisions are carried out, before
+	// the starving employees are counted and the computers mine
+	BeforeStarvation EventPhase = iota
+	// AfterMining events happen once the year's bitcoin has been mined
+	AfterMining
+	// NewPrices events happen once next year's computer price has been set
+	NewPrices
+)
+
+// Event is something that may happen to the corporation during a year. Events are declared
+// in the rules with an EventConfig, which sets their chance and how hard they hit.
+type Event interface {
+	// Name is how the event is declared in the rules
+	Name() string
+	// Phase says when in the year the event is rolled
+	Phase() EventPhase
+	// CanHappen reports whether the event's preconditions hold
+	CanHappen(s GameState, r YearReport) bool
+	// Happen applies the event to the state and the year's report with the rolled magnitude,
+	// and returns the message shown to the player, in the active locale
+	Happen(s *GameState, r *YearReport, magnitude int) string
+}
+
+// chanceAdjuster is implemented by events whose chance depends on the state of the corporation
+type chanceAdjuster interface {
+	// adjustChance returns the chance of the event this year, given the chance declared in the rules
+	adjustChance(s GameState, chance int) int
+}
+
+// magnitudeLimiter is implemented by events that cannot be declared with a magnitude of up
+// to 100 percent
+type magnitudeLimiter interface {
+	// maxMagnitude returns the largest magnitude the event can be declared with
+	maxMagnitude() int
+}
+
+// EventConfig declares an event in the rules. Every year the event happens with a chance
+// of Chance percent, and its magnitude is rolled between Min and Max. What the magnitude
+// means is up to the event; for the built-in events it is a percentage.
+type EventConfig struct {
+	Name   string `json:"name"`
+	Chance int    `json:"chance"`
+	Min    int    `json:"min"`
+	Max    int    `json:"max"`
+}
+
+// EventOutcome records an event that happened
+type EventOutcome struct {
+	Year      int    `json:"year"`
+	Name      string `json:"name"`
+	Magnitude int    `json:"magnitude"`
+	Message   string `json:"message"`
+}
+
+// events holds every event that can be declared in the rules, by name
+var events = map[string]Event{}
+
+// RegisterEvent makes an event available to the rules. Registering a name twice replaces
+// the earlier event.
+func RegisterEvent(e Event) {
+	events[e.Name()] = e
+}
+
+// EventNames returns the names of the registered events in alphabetical order
+func EventNames() []string {
+	var names []string
+	for name := range events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterEvent(marketCrash{})
+	RegisterEvent(hackers{})
+	RegisterEvent(hardwareFailure{})
+	RegisterEvent(regulatoryFine{})
+	RegisterEvent(bullRun{})
+	RegisterEvent(staffStrike{})
+}
+
+// validateEvent checks an event declared in the rules
+func validateEvent(cfg EventConfig) error {
+	e, ok := events[cfg.Name]
+	if !ok {
+		return fmt.Errorf("unknown event %q, expected one of %v", cfg.Name, EventNames())
+	}
+	if cfg.Chance < 0 || cfg.Chance > 100 {
+		return fmt.Errorf("event %s: chance must be between 0 and 100, not %d", cfg.Name, cfg.Chance)
+	}
+	limit := 100
+	if l, ok := e.(magnitudeLimiter); ok {
+		limit = l.maxMagnitude()
+	}
+	if cfg.Min < 0 || cfg.Max > limit {
+		return fmt.Errorf("event %s: magnitude must be between 0 and %d percent, not %d to %d", cfg.Name, limit, cfg.Min, cfg.Max)
+	}
+	if cfg.Min > cfg.Max {
+		return fmt.Errorf("event %s: min is larger than max", cfg.Name)
+	}
+	return nil
+}
+
+// rollEvents rolls every event declared in the rules for phase, in the order they are declared
+func rollEvents(phase EventPhase, s *GameState, r *YearReport, rng *rand.Rand) {
+	for _, cfg := range s.Rules.Events {
+		e, ok := events[cfg.Name]
+		if !ok || e.Phase() != phase || !e.CanHappen(*s, *r) {
+			continue
+		}
+		chance := cfg.Chance
+		if a, ok := e.(chanceAdjuster); ok {
+			chance = min(max(a.adjustChance(*s, chance), 0), 100)
+		}
+		if rng.IntN(100) >= chance {
+			continue
+		}
+
+		magnitude := cfg.Min
+		if cfg.Max > cfg.Min {
+			magnitude = magnitude + rng.IntN(cfg.Max-cfg.Min+1)
+		}
+		message := e.Happen(s, r, magnitude)
+		r.Events = append(r.Events, EventOutcome{
+			Year:      s.Year,
+			Name:      cfg.Name,
+			Magnitude: magnitude,
+			Message:   message,
+		})
+	}
+}
+
+// marketCrash wipes out a percentage of the employees, and knocks the same percentage
+// off the price of a bitcoin in dollars
+type marketCrash struct{}
+
+func (marketCrash) Name() string      { return "crash" }
+func (marketCrash) Phase() EventPhase { return BeforeStarvation }
+
+func (marketCrash) CanHappen(s GameState, r YearReport) bool {
+	return s.Employees > 0
+}
+
+// maxMagnitude keeps a crash from wiping out every employee, which would leave no one to run
+// the corporation
+func (marketCrash) maxMagnitude() int { return 99 }
+
+func (marketCrash) Happen(s *GameState, r *YearReport, magnitude int) string {
+	victims := (s.Employees * magnitude) / 100
+	s.Employees = s.Employees - victims
+	s.MarketCrashVictims = victims
+	r.MarketCrashVictims = victims
+	s.ExchangeRate = max(s.ExchangeRate-(s.ExchangeRate*magnitude)/100, 1)
+	return Text("event.crash", victims, s.ExchangeRate)
+}
+
+// hackers steal a percentage of the cash. Disgruntled employees let them in more often, and
+// loyal ones keep them out.
+type hackers struct{}
+
+func (hackers) Name() string      { return "hackers" }
+func (hackers) Phase() EventPhase { return AfterMining }
+
+func (hackers) CanHappen(s GameState, r YearReport) bool {
+	return s.Cash > 0
+}
+
+func (hackers) adjustChance(s GameState, chance int) int {
+	return chance + (neutralMorale-s.Morale)/2
+}
+
+func (hackers) Happen(s *GameState, r *YearReport, magnitude int) string {
+	stolen := (magnitude * s.Cash) / 100
+	s.Cash = s.Cash - stolen
+	s.AmountStolenByHackers = stolen
+	r.PercentHacked = magnitude
+	r.AmountStolenByHackers = stolen
+	return Text("event.hackers", magnitude)
+}
+
+// hardwareFailure breaks a percentage of the computers beyond repair
+type hardwareFailure struct{}
+
+func (hardwareFailure) Name() string      { return "hardware-failure" }
+func (hardwareFailure) Phase() EventPhase { return AfterMining }
+
+func (hardwareFailure) CanHappen(s GameState, r YearReport) bool {
+	return s.Computers > 0
+}
+
+func (hardwareFailure) Happen(s *GameState, r *YearReport, magnitude int) string {
+	broken := s.breakDown(func(Batch) int { return magnitude })
+	return Plural("event.hardware-failure", broken, broken)
+}
+
+// regulatoryFine takes a percentage of the cash
+type regulatoryFine struct{}
+
+func (regulatoryFine) Name() string      { return "regulatory-fine" }
+func (regulatoryFine) Phase() EventPhase { return AfterMining }
+
+func (regulatoryFine) CanHappen(s GameState, r YearReport) bool {
+	return s.Cash > 0
+}
+
+func (regulatoryFine) Happen(s *GameState, r *YearReport, magnitude int) string {
+	fine := (s.Cash * magnitude) / 100
+	s.Cash = s.Cash - fine
+	return Text("event.regulatory-fine", fine)
+}
+
+// bullRun drives next year's computer price up by a percentage
+type bullRun struct{}
+
+func (bullRun) Name() string      { return "bull-run" }
+func (bullRun) Phase() EventPhase { return NewPrices }
+
+func (bullRun) CanHappen(s GameState, r YearReport) bool {
+	return true
+}
+
+func (bullRun) Happen(s *GameState, r *YearReport, magnitude int) string {
+	s.ComputerPrice = s.ComputerPrice + (s.ComputerPrice*magnitude)/100
+	return Text("event.bull-run", magnitude)
+}
+
+// staffStrike leaves a percentage of the maintained computers idle for the year
+type staffStrike struct{}
+
+func (staffStrike) Name() string      { return "staff-strike" }
+func (staffStrike) Phase() EventPhase { return BeforeStarvation }
+
+func (staffStrike) CanHappen(s GameState, r YearReport) bool {
+	return r.ComputersMaintained > 0
+}
+
+func (staffStrike) Happen(s *GameState, r *YearReport, magnitude int) string {
+	idle := (r.ComputersMaintained * magnitude) / 100
+	r.ComputersMaintained = r.ComputersMaintained - idle
+	return Plural("event.staff-strike", idle, idle)
+}
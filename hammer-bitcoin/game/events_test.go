@@ -0,0 +1,69 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateEvent(t *testing.T) {
+	tests := []struct {
+		cfg  EventConfig
+		want string
+	}{
+		{cfg: EventConfig{Name: "crash", Chance: 100, Min: 99, Max: 99}},
+		{cfg: EventConfig{Name: "hackers", Chance: 100, Min: 100, Max: 100}},
+		{cfg: EventConfig{Name: "crash", Chance: 100, Min: 100, Max: 100}, want: "between 0 and 99 percent"},
+		{cfg: EventConfig{Name: "hackers", Chance: 50, Min: -1, Max: 10}, want: "between 0 and 100 percent"},
+		{cfg: EventConfig{Name: "hackers", Chance: 101, Min: 10, Max: 10}, want: "chance must be between 0 and 100"},
+		{cfg: EventConfig{Name: "hackers", Chance: 50, Min: 20, Max: 10}, want: "min is larger than max"},
+		{cfg: EventConfig{Name: "meteor", Chance: 50}, want: "unknown event"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cfg.Name, func(t *testing.T) {
+			err := validateEvent(tt.cfg)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("got error %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestWorstCrash is a regression test for a crash that wiped out every employee, after which
+// counting the new hires divided by zero
+func TestWorstCrash(t *testing.T) {
+	rules := DefaultRules.Clone()
+	rules.Events = []EventConfig{{Name: "crash", Chance: 100, Min: 100, Max: 100}}
+	if err := rules.Validate(); err == nil {
+		t.Error("a crash of 100 percent was accepted")
+	}
+
+	rules.Events = []EventConfig{{Name: "crash", Chance: 100, Min: 99, Max: 99}}
+	if err := rules.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for seed := uint64(1); seed <= 10; seed++ {
+		s, err := RunGame(Conservative{}, rules, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the crashes can leave few enough employees to starve, which ends the term
+		if s.Employees == 0 && !s.Evicted {
+			t.Errorf("seed %d: no employees left in year %d", seed, s.Year)
+		}
+	}
+}
+
+func TestStepWithoutEmployees(t *testing.T) {
+	s := testState()
+	s.Employees = 0
+	s, r := Step(s, Decisions{}, NewRand(1))
+	if r.NewEmployees != 0 || s.Employees != 0 {
+		t.Errorf("got %d new employees and %d in all, want none", r.NewEmployees, s.Employees)
+	}
+}
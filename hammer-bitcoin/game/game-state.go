@@ -0,0 +1,246 @@
+package game
+
+import "math/rand/v2"
+
+// GameState holds everything about the corporation that carries over from one year to the next
+type GameState struct {
+	Year                        int  `json:"year"`
+	Employees                   int  `json:"employees"`
+	Cash                        int  `json:"cash"`
+	Fiat                        int  `json:"fiat"`
+	ExchangeRate                int  `json:"exchangeRate"`
+	Computers                   int  `json:"computers"`
+	ComputerPrice               int  `json:"computerPrice"`
+	Starved                     int  `json:"starved"`
+	MarketCrashVictims          int  `json:"marketCrashVictims"`
+	NewEmployees                int  `json:"newEmployees"`
+	CashMined                   int  `json:"cashMined"`
+	BitcoinGeneratedPerComputer int  `json:"bitcoinGeneratedPerComputer"`
+	AmountStolenByHackers       int  `json:"amountStolenByHackers"`
+	Evicted                     bool `json:"evicted"`
+	// Morale is how the employees feel about the corporation, from 0 to 100
+	Morale int `json:"morale"`
+	// Skill is the employees' average experience, from 0 to 100
+	Skill int `json:"skill"`
+	// Fleet is the corporation's computers, by generation and age. Computers is their total.
+	Fleet []Batch `json:"fleet"`
+	// Debt is what the corporation owes the bank
+	Debt int `json:"debt"`
+	// Bankrupt is whether the bank could not be paid, which ends the term
+	Bankrupt bool  `json:"bankrupt"`
+	Rules    Rules `json:"rules"`
+	// Events are the random events that happened during the previous year
+	Events []EventOutcome `json:"events"`
+
+	// buying is the generation the computers bought this year are of, while the year's
+	// decisions are being carried out
+	buying string
+}
+
+// Summary is the read-only view of the corporation at the start of a year that
+// printSummary shows the player
+type Summary struct {
+	Year                        int            `json:"year"`
+	MarketCrashVictims          int            `json:"marketCrashVictims"`
+	Starved                     int            `json:"starved"`
+	NewEmployees                int            `json:"newEmployees"`
+	Employees                   int            `json:"employees"`
+	CashMined                   int            `json:"cashMined"`
+	BitcoinGeneratedPerComputer int            `json:"bitcoinGeneratedPerComputer"`
+	AmountStolenByHackers       int            `json:"amountStolenByHackers"`
+	Cash                        int            `json:"cash"`
+	Fiat                        int            `json:"fiat"`
+	ExchangeRate                int            `json:"exchangeRate"`
+	Computers                   int            `json:"computers"`
+	ComputerPrice               int            `json:"computerPrice"`
+	Morale                      int            `json:"morale"`
+	Skill                       int            `json:"skill"`
+	Fleet                       []Holding      `json:"fleet"`
+	Catalog                     []Offer        `json:"catalog"`
+	Debt                        int            `json:"debt"`
+	Credit                      int            `json:"credit"`
+	InterestRate                int            `json:"interestRate"`
+	Events                      []EventOutcome `json:"events"`
+	Rules                       Rules          `json:"rules"`
+}
+
+// Decisions holds the choices the player makes each year
+type Decisions struct {
+	// Borrow is taken out as a loan from the bank; a negative amount repays that much of the debt
+	Borrow int `json:"borrow"`
+	// BitcoinToSell is sold for dollars; a negative amount buys bitcoin with dollars
+	BitcoinToSell int `json:"bitcoinToSell"`
+	// Generation is the generation of the computers to buy. Empty means the first generation.
+	Generation          string `json:"generation,omitempty"`
+	ComputersToBuy      int    `json:"computersToBuy"`
+	ComputersToSell     int    `json:"computersToSell"`
+	CashPaidToEmployees int    `json:"cashPaidToEmployees"`
+	MaintenanceAmount   int    `json:"maintenanceAmount"`
+}
+
+// YearReport describes what happened during a year once the decisions were applied
+type YearReport struct {
+	ComputersMaintained         int            `json:"computersMaintained"`
+	MarketCrashVictims          int            `json:"marketCrashVictims"`
+	Starved                     int            `json:"starved"`
+	PercentStarved              int            `json:"percentStarved"`
+	NewEmployees                int            `json:"newEmployees"`
+	CashMined                   int            `json:"cashMined"`
+	BitcoinGeneratedPerComputer int            `json:"bitcoinGeneratedPerComputer"`
+	PercentHacked               int            `json:"percentHacked"`
+	AmountStolenByHackers       int            `json:"amountStolenByHackers"`
+	Events                      []EventOutcome `json:"events"`
+	Evicted                     bool           `json:"evicted"`
+	// MoraleChange is how much the morale moved during the year
+	MoraleChange int `json:"moraleChange"`
+	// ComputersFailed is how many computers wore out during the year
+	ComputersFailed int `json:"computersFailed"`
+	// InterestPaid and DebtCalledIn are what the bank was owed at the end of the year: the
+	// interest on the debt, and the debt that was over the credit limit
+	InterestPaid int `json:"interestPaid"`
+	DebtCalledIn int `json:"debtCalledIn"`
+	// DollarsSeized and ComputersSeized are how many dollars the bank took and how many computers
+	// it sold because the cash did not cover what it was owed
+	DollarsSeized   int  `json:"dollarsSeized"`
+	ComputersSeized int  `json:"computersSeized"`
+	Bankrupt        bool `json:"bankrupt"`
+}
+
+// NewRand returns the random number generator used by the game, seeded so that
+// the same seed always produces the same game
+func NewRand(seed uint64) *rand.Rand {
+	return rand.New(newSource(seed))
+}
+
+// newSource returns the source behind NewRand. Unlike the generator, the source
+// can be saved and restored with MarshalBinary and UnmarshalBinary.
+func newSource(seed uint64) *rand.PCG {
+	return rand.NewPCG(seed, seed)
+}
+
+// NewGame returns the state at the start of a new term played by rules
+func NewGame(rules Rules, rng *rand.Rand) GameState {
+	return GameState{
+		Year:                        1,
+		Employees:                   rules.StartingEmployees,
+		Cash:                        rules.StartingCash,
+		Fiat:                        rules.StartingFiat,
+		ExchangeRate:                rules.StartingExchangeRate,
+		Computers:                   rules.StartingComputers,
+		Fleet:                       []Batch{{Generation: rules.Generations[0].Name, Count: rules.StartingComputers}},
+		ComputerPrice:               updateComputerPrice(rng),
+		Rules:                       rules,
+		Starved:                     0,
+		MarketCrashVictims:          0,
+		NewEmployees:                5,
+		CashMined:                   3000,
+		BitcoinGeneratedPerComputer: 3,
+		AmountStolenByHackers:       200,
+		Morale:                      neutralMorale,
+	}
+}
+
+// Finished reports whether the term is over, either because the term has run its
+// course or because the player was evicted or went bankrupt
+func (s GameState) Finished() bool {
+	return s.Evicted || s.Bankrupt || s.Year > s.Rules.TermYears
+}
+
+// Summary returns the year summary shown to the player
+func (s GameState) Summary() Summary {
+	return Summary{
+		Year:                        s.Year,
+		MarketCrashVictims:          s.MarketCrashVictims,
+		Starved:                     s.Starved,
+		NewEmployees:                s.NewEmployees,
+		Employees:                   s.Employees,
+		CashMined:                   s.CashMined,
+		BitcoinGeneratedPerComputer: s.BitcoinGeneratedPerComputer,
+		AmountStolenByHackers:       s.AmountStolenByHackers,
+		Cash:                        s.Cash,
+		Fiat:                        s.Fiat,
+		ExchangeRate:                s.ExchangeRate,
+		Computers:                   s.Computers,
+		ComputerPrice:               s.ComputerPrice,
+		Morale:                      s.Morale,
+		Skill:                       s.Skill,
+		Fleet:                       s.holdings(),
+		Catalog:                     s.Catalog(),
+		Debt:                        s.Debt,
+		Credit:                      s.Credit(),
+		InterestRate:                s.Rules.InterestRate,
+		Events:                      s.Events,
+		Rules:                       s.Rules,
+	}
+}
+
+// Step plays one year: it applies the player's decisions, rolls the random events and
+// returns the state for the next year along with a report of what happened.
+// Step never reads from stdin or writes to stdout, and all randomness comes from rng.
+func Step(s GameState, d Decisions, rng *rand.Rand) (GameState, YearReport) {
+	s, report := operate(s, d, rng)
+
+	s.ComputerPrice = updateComputerPrice(rng)
+	s.ExchangeRate = updateExchangeRate(rng, s.Rules, s.ExchangeRate)
+	rollEvents(NewPrices, &s, &report, rng)
+
+	endYear(&s, &report)
+	return s, report
+}
+
+// operate plays the part of a year that happens inside the corporation: it applies the
+// decisions, counts the starved and the new hires, mines and rolls the events before the
+// market sets next year's prices
+func operate(s GameState, d Decisions, rng *rand.Rand) (GameState, YearReport) {
+	var report YearReport
+
+	s.borrow(d.Borrow)
+	s.convert(d.BitcoinToSell)
+	s.choose(d.Generation)
+	s.buy(d.ComputersToBuy)
+	s.sell(d.ComputersToSell)
+	s.pay(d.CashPaidToEmployees)
+	// maintenance is paid out of the bitcoin that gets mined, so it does not come out of cash
+	report.ComputersMaintained = computersMaintained(s, d.MaintenanceAmount)
+	morale := s.Morale
+
+	s.MarketCrashVictims = 0
+	s.AmountStolenByHackers = 0
+	rollEvents(BeforeStarvation, &s, &report, rng)
+
+	s.Starved, report.PercentStarved = countStarvedEmployees(s.Employees, d.CashPaidToEmployees, s.Rules.LivingCost)
+	s.updateMorale(s.Employees, d.CashPaidToEmployees, report.PercentStarved)
+	report.MoraleChange = s.Morale - morale
+	s.Employees = s.Employees - s.Starved
+	report.Starved = s.Starved
+	if report.PercentStarved >= s.Rules.EvictionThreshold {
+		s.Evicted = true
+	}
+
+	s.NewEmployees = moraleHires(countNewHires(s.Starved, s.Computers, s.Cash, s.Employees), s.Morale)
+	s.gainExperience(s.Employees, s.NewEmployees)
+	s.Employees = s.Employees + s.NewEmployees
+	report.NewEmployees = s.NewEmployees
+
+	s.BitcoinGeneratedPerComputer, s.CashMined = mineBitCoin(rng, s, report.ComputersMaintained)
+	s.Cash = s.Cash + s.CashMined
+	report.BitcoinGeneratedPerComputer = s.BitcoinGeneratedPerComputer
+	report.CashMined = s.CashMined
+	report.ComputersFailed = s.wearOut()
+
+	rollEvents(AfterMining, &s, &report, rng)
+	s.settleDebt(&report)
+	return s, report
+}
+
+// endYear closes the year once next year's prices are set
+func endYear(s *GameState, report *YearReport) {
+	s.Events = report.Events
+	s.Year = s.Year + 1
+	report.Evicted = s.Evicted
+}
+
+// pay takes the living expenses handed out to employees out of cash
+func (s *GameState) pay(cashPaidToEmployees int) {
+	s.Cash = s.Cash - cashPaidToEmployees
+}
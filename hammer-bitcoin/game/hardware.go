@@ -0,0 +1,347 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Generation is a kind of computer the corporation can mine with. Newer generations come on
+// sale later in the term, and mine more for their power than the ones before them.
+type Generation struct {
+	// Name identifies the generation in the rules and in the decisions
+	Name string `json:"name"`
+	// Since is the first year of the term the generation is on sale
+	Since int `json:"since"`
+	// Price is what one costs, in percent of the market price of a computer
+	Price int `json:"price"`
+	// Yield is what one mines, in percent of the bitcoins rolled per computer each year
+	Yield int `json:"yield"`
+	// Power is the bitcoins it takes to keep one running for a year
+	Power int `json:"power"`
+	// FailureRate is the percentage of them that break down beyond repair every year
+	FailureRate int `json:"failureRate"`
+}
+
+// DefaultGenerations are the computers on sale in the built-in presets. The corporation
+// starts with the first of them, which mines and costs what every computer did in the original game.
+var DefaultGenerations = []Generation{
+	{Name: "standard", Since: 1, Price: 100, Yield: 100, Power: 2, FailureRate: 2},
+	{Name: "refurbished", Since: 1, Price: 50, Yield: 70, Power: 2, FailureRate: 8},
+	{Name: "pro", Since: 4, Price: 170, Yield: 160, Power: 3, FailureRate: 2},
+	{Name: "asic", Since: 7, Price: 300, Yield: 260, Power: 4, FailureRate: 1},
+}
+
+// minResaleValue is the least a computer sells for however old it is, in percent of its price new
+const minResaleValue = 10
+
+// Batch is a number of computers of one generation that were bought in the same year
+type Batch struct {
+	Generation string `json:"generation"`
+	Count      int    `json:"count"`
+	// Age is how many years the computers have been running
+	Age int `json:"age"`
+}
+
+// Offer is a generation on sale this year, as listed in the catalog
+type Offer struct {
+	// Number is how the player picks the offer, counting from 1
+	Number      int    `json:"number"`
+	Generation  string `json:"generation"`
+	Price       int    `json:"price"`
+	Yield       int    `json:"yield"`
+	Power       int    `json:"power"`
+	FailureRate int    `json:"failureRate"`
+}
+
+// validateGenerations checks the generations declared in the rules
+func validateGenerations(gens []Generation) error {
+	if len(gens) == 0 {
+		return fmt.Errorf("at least one generation of computers must be declared")
+	}
+	if gens[0].Since != 1 {
+		return fmt.Errorf("generation %s: the first generation must be on sale from year 1, not %d", gens[0].Name, gens[0].Since)
+	}
+	declared := map[string]bool{}
+	for _, g := range gens {
+		switch {
+		case g.Name == "":
+			return fmt.Errorf("every generation needs a name")
+		case declared[g.Name]:
+			return fmt.Errorf("generation %s is declared more than once", g.Name)
+		case g.Since < 1:
+			return fmt.Errorf("generation %s: since must be at least 1, not %d", g.Name, g.Since)
+		case g.Price < 1:
+			return fmt.Errorf("generation %s: price must be at least 1, not %d", g.Name, g.Price)
+		case g.Yield < 0:
+			return fmt.Errorf("generation %s: yield cannot be negative, not %d", g.Name, g.Yield)
+		case g.Power < 1:
+			return fmt.Errorf("generation %s: power must be at least 1, not %d", g.Name, g.Power)
+		case g.FailureRate < 0 || g.FailureRate > 100:
+			return fmt.Errorf("generation %s: failureRate must be between 0 and 100, not %d", g.Name, g.FailureRate)
+		}
+		declared[g.Name] = true
+	}
+	return nil
+}
+
+// generationName returns the name of a generation as shown to the player, in the active locale.
+// A generation declared in a rules file has no translation, and is shown as it is declared.
+func generationName(name string) string {
+	id := "generation." + name
+	if text := Text(id); text != id {
+		return text
+	}
+	return name
+}
+
+// specOf returns the generation of a batch. A batch of a generation the rules no longer
+// declare is taken to be of the first generation.
+func (s GameState) specOf(b Batch) Generation {
+	if g, ok := s.generation(b.Generation); ok {
+		return g
+	}
+	return s.Rules.Generations[0]
+}
+
+// generation returns the generation called name. An empty name is the first generation declared.
+func (s GameState) generation(name string) (Generation, bool) {
+	gens := s.Rules.Generations
+	if name == "" && len(gens) > 0 {
+		return gens[0], true
+	}
+	for _, g := range gens {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return Generation{}, false
+}
+
+// priceOf returns what a computer of generation g costs this year
+func (s GameState) priceOf(g Generation) int {
+	return max(s.ComputerPrice*g.Price/100, 1)
+}
+
+// Catalog returns the generations on sale this year, in the order they are declared
+func (s GameState) Catalog() []Offer {
+	var catalog []Offer
+	for _, g := range s.Rules.Generations {
+		if g.Since > s.Year {
+			continue
+		}
+		catalog = append(catalog, Offer{
+			Number:      len(catalog) + 1,
+			Generation:  g.Name,
+			Price:       s.priceOf(g),
+			Yield:       g.Yield,
+			Power:       g.Power,
+			FailureRate: g.FailureRate,
+		})
+	}
+	return catalog
+}
+
+// offer returns the offer the player picked by number from this year's catalog
+func (s GameState) offer(number int) (Offer, error) {
+	catalog := s.Catalog()
+	if number < 1 || number > len(catalog) {
+		return Offer{}, &DecisionError{Decision: DecisionGeneration, Amount: number, Available: len(catalog), Err: ErrNotOnSale}
+	}
+	return catalog[number-1], nil
+}
+
+// buy adds computers of the chosen generation at this year's price and pays for them
+func (s *GameState) buy(computersToBuy int) {
+	g, _ := s.generation(s.buying)
+	s.Cash = s.Cash - s.priceOf(g)*computersToBuy
+	if computersToBuy <= 0 {
+		return
+	}
+
+	// copies of a state share their fleet, so the fleet is never changed in place
+	fleet := append([]Batch(nil), s.Fleet...)
+	for i, b := range fleet {
+		if b.Generation == g.Name && b.Age == 0 {
+			fleet[i].Count = b.Count + computersToBuy
+			s.Fleet = fleet
+			s.countComputers()
+			return
+		}
+	}
+	s.Fleet = append(fleet, Batch{Generation: g.Name, Count: computersToBuy})
+	s.countComputers()
+}
+
+// sell sells the oldest computers at their resale value
+func (s *GameState) sell(computersToSell int) {
+	s.Cash = s.Cash + s.saleValue(computersToSell)
+	s.retire(computersToSell)
+}
+
+// choose picks the generation the computers bought this year are of
+func (s *GameState) choose(generation string) {
+	s.buying = generation
+}
+
+// resaleValue returns what one computer of the batch sells for this year. Computers lose the
+// rules' depreciation of their price new every year, down to minResaleValue percent.
+func (s GameState) resaleValue(b Batch) int {
+	g := s.specOf(b)
+	percent := max(100-s.Rules.Depreciation*b.Age, minResaleValue)
+	return s.priceOf(g) * percent / 100
+}
+
+// saleValue returns what the n oldest computers sell for together
+func (s GameState) saleValue(n int) int {
+	value := 0
+	for _, b := range s.oldestFirst() {
+		sold := min(n, b.Count)
+		value = value + sold*s.resaleValue(b)
+		n = n - sold
+	}
+	return value
+}
+
+// oldestFirst returns the fleet in the order computers are sold: the oldest first
+func (s GameState) oldestFirst() []Batch {
+	fleet := append([]Batch(nil), s.Fleet...)
+	sort.SliceStable(fleet, func(i, j int) bool { return fleet[i].Age > fleet[j].Age })
+	return fleet
+}
+
+// maintenanceOrder returns the fleet in the order computers are kept running: the ones that
+// mine the most for their power first, and of those the youngest first
+func (s GameState) maintenanceOrder() []Batch {
+	fleet := append([]Batch(nil), s.Fleet...)
+	efficiency := func(b Batch) int {
+		g := s.specOf(b)
+		return 1000 * g.Yield / g.Power
+	}
+	sort.SliceStable(fleet, func(i, j int) bool {
+		if ei, ej := efficiency(fleet[i]), efficiency(fleet[j]); ei != ej {
+			return ei > ej
+		}
+		return fleet[i].Age < fleet[j].Age
+	})
+	return fleet
+}
+
+// powerCost returns the bitcoins it takes to keep the first n computers in maintenance order running
+func (s GameState) powerCost(n int) int {
+	cost := 0
+	for _, b := range s.maintenanceOrder() {
+		g := s.specOf(b)
+		running := min(n, b.Count)
+		cost = cost + running*g.Power
+		n = n - running
+	}
+	return cost
+}
+
+// maintainable returns how many computers, in maintenance order, a budget of bitcoins keeps running
+func (s GameState) maintainable(budget int) int {
+	maintained := 0
+	for _, b := range s.maintenanceOrder() {
+		g := s.specOf(b)
+		running := min(budget/g.Power, b.Count)
+		maintained = maintained + running
+		budget = budget - running*g.Power
+		if running < b.Count {
+			break
+		}
+	}
+	return maintained
+}
+
+// hashPower returns what the first n computers in maintenance order mine together, in percent
+// of the bitcoins rolled per computer
+func (s GameState) hashPower(n int) int {
+	power := 0
+	for _, b := range s.maintenanceOrder() {
+		g := s.specOf(b)
+		running := min(n, b.Count)
+		power = power + running*g.Yield
+		n = n - running
+	}
+	return power
+}
+
+// countComputers brings the computer count up to date with the fleet
+func (s *GameState) countComputers() {
+	s.Computers = 0
+	for _, b := range s.Fleet {
+		s.Computers = s.Computers + b.Count
+	}
+}
+
+// retire removes the n oldest computers from the fleet
+func (s *GameState) retire(n int) {
+	var fleet []Batch
+	for _, b := range s.oldestFirst() {
+		gone := min(n, b.Count)
+		b.Count = b.Count - gone
+		n = n - gone
+		if b.Count > 0 {
+			fleet = append(fleet, b)
+		}
+	}
+	sort.SliceStable(fleet, func(i, j int) bool { return fleet[i].Age < fleet[j].Age })
+	s.Fleet = fleet
+	s.countComputers()
+}
+
+// breakDown breaks percent of every batch beyond repair, and returns how many computers broke
+func (s *GameState) breakDown(percent func(Batch) int) int {
+	broken := 0
+	var fleet []Batch
+	for _, b := range s.Fleet {
+		gone := b.Count * percent(b) / 100
+		b.Count = b.Count - gone
+		broken = broken + gone
+		if b.Count > 0 {
+			fleet = append(fleet, b)
+		}
+	}
+	s.Fleet = fleet
+	s.countComputers()
+	return broken
+}
+
+// wearOut breaks down the share of every generation its failure rate says, and ages the rest
+// by a year. It returns how many computers broke down.
+func (s *GameState) wearOut() int {
+	failed := s.breakDown(func(b Batch) int {
+		g := s.specOf(b)
+		return g.FailureRate
+	})
+	// breakDown left the state with a fleet of its own, so it can be aged in place
+	for i := range s.Fleet {
+		s.Fleet[i].Age++
+	}
+	return failed
+}
+
+// Holding is how many computers of a generation the corporation owns, and what they would sell for
+type Holding struct {
+	Generation string `json:"generation"`
+	Count      int    `json:"count"`
+	Value      int    `json:"value"`
+}
+
+// holdings adds up the fleet by generation, in the order the generations are declared
+func (s GameState) holdings() []Holding {
+	var holdings []Holding
+	for _, g := range s.Rules.Generations {
+		h := Holding{Generation: g.Name}
+		for _, b := range s.Fleet {
+			if b.Generation == g.Name {
+				h.Count = h.Count + b.Count
+				h.Value = h.Value + b.Count*s.resaleValue(b)
+			}
+		}
+		if h.Count > 0 {
+			holdings = append(holdings, h)
+		}
+	}
+	return holdings
+}
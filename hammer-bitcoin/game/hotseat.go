@@ -0,0 +1,77 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// PlayHotSeat plays a match in the terminal, the players taking turns at the keyboard.
+// Every player decides in private, and everyone sees what happened once the year is played.
+func PlayHotSeat(rules Rules, seed uint64, names []string) error {
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	m, err := NewMatch(rules, seed, names)
+	if err != nil {
+		return err
+	}
+
+	printIntroductoryParagraph(rules)
+	fmt.Println(Text("match.market", len(names)))
+	fmt.Println("")
+	in := newConsole(nil, rules, seed)
+
+	for !m.Finished() {
+		decisions := map[string]Decisions{}
+		for _, p := range m.Active() {
+			in.pause(Text("match.pass", p.Name))
+			clearScreen()
+			color.Green("%s", Text("match.corporation", p.Name))
+			printSummary(p.State.Summary())
+			d, err := askForDecisions(in, p.State)
+			if err != nil {
+				return err
+			}
+			decisions[p.Name] = d
+			fmt.Println("")
+		}
+
+		active := m.Active()
+		if err := m.Resolve(decisions); err != nil {
+			return err
+		}
+
+		clearScreen()
+		fmt.Println(Text("match.yearOver", m.Year-1))
+		for _, p := range active {
+			fmt.Println("")
+			color.Green("%s", Text("match.corporation", p.Name))
+			printYearEvents(p.LastYear)
+			if p.State.Evicted {
+				color.Red("%s", Text("match.evicted", p.Name))
+			}
+			if p.State.Bankrupt {
+				color.Red("%s", Text("match.bankrupt", p.Name))
+			}
+		}
+		fmt.Println("")
+	}
+
+	in.pause(Text("match.finalPrompt"))
+	printStandings(m)
+	return nil
+}
+
+// printStandings prints the final standings of a finished match and the winner's verdict
+func printStandings(m *Match) {
+	clearScreen()
+	standings := m.Standings()
+	PrintStandings(color.Output, standings)
+
+	winner := standings[0]
+	fmt.Println("")
+	color.Yellow("%s", Text("match.wins", winner.Name))
+	fmt.Println(winner.Score.Message)
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrEndOfInput is returned when the answers run out before the game is over
+var ErrEndOfInput = errors.New("the input ended before the game did")
+
+// input is where the player's answers are read from. Every prompt reads from the same
+// buffered reader, so that no answer is lost to another prompt's buffer.
+var input = bufio.NewReader(os.Stdin)
+
+// interactive is whether a player is typing the answers in a terminal
+var interactive = isTerminal(os.Stdin)
+
+// terminalOutput is whether the game is shown in a terminal, and so may use escape codes.
+// Colors are turned off by the color package itself when it is not.
+var terminalOutput = isTerminal(os.Stdout)
+
+// SetInput makes the game read its answers line by line from r, such as a file of answers,
+// instead of from the player at the keyboard
+func SetInput(r io.Reader) {
+	input = bufio.NewReader(r)
+	interactive = false
+}
+
+// isTerminal reports whether f is a terminal rather than a file or a pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// readLine reads the next answer, without its line ending. When the answers are not typed,
+// the answer is echoed after the prompt so that the transcript reads as if they had been.
+// It returns ErrEndOfInput once there are no answers left.
+func readLine() (string, error) {
+	line, err := input.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		if errors.Is(err, io.EOF) {
+			err = ErrEndOfInput
+		}
+		if !interactive {
+			fmt.Println("")
+		}
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if !interactive {
+		fmt.Println(line)
+	}
+	return line, nil
+}
@@ -0,0 +1,295 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// The kinds of matchMessage
+const (
+	msgJoin      = "join"
+	msgWait      = "wait"
+	msgYear      = "year"
+	msgDecisions = "decisions"
+	msgRejected  = "rejected"
+	msgReport    = "report"
+	msgStandings = "standings"
+)
+
+// joinTimeout is how long someone who connects to the host has to say who they are before
+// the next player is let in
+const joinTimeout = 10 * time.Second
+
+// decisionTimeout is how long a player has to decide a year. A player who takes longer is
+// evicted, as if they had left, so that one silent player cannot hold up the match.
+const decisionTimeout = 10 * time.Minute
+
+// matchMessage is what the host and the players of a LAN match send each other, one JSON
+// value at a time. Which fields are set depends on the type.
+type matchMessage struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name,omitempty"`
+	Message   string      `json:"message,omitempty"`
+	State     *GameState  `json:"state,omitempty"`
+	Decisions *Decisions  `json:"decisions,omitempty"`
+	Report    *YearReport `json:"report,omitempty"`
+	Standings []Standing  `json:"standings,omitempty"`
+}
+
+// seat is a player connected to the host
+type seat struct {
+	name string
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// send sends msg to the player, returning any error
+func (s *seat) send(msg matchMessage) error {
+	return s.enc.Encode(msg)
+}
+
+// HostMatch waits on addr for seats players to join with JoinMatch, then plays a match
+// between them. A player who disconnects, or takes longer than decisionTimeout to decide a
+// year, is evicted.
+func HostMatch(addr string, rules Rules, seed uint64, seats int) error {
+	if seats < MinPlayers || seats > MaxPlayers {
+		return fmt.Errorf("a match needs %d to %d players, not %d", MinPlayers, MaxPlayers, seats)
+	}
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	fmt.Printf("Hosting a %d player match on %s. Players join with -join %s\n", seats, ln.Addr(), ln.Addr())
+
+	players, err := acceptPlayers(ln, seats)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, s := range players {
+			s.conn.Close()
+		}
+	}()
+
+	var names []string
+	for _, s := range players {
+		names = append(names, s.name)
+	}
+	m, err := NewMatch(rules, seed, names)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("The match is on between %v, seed %d.\n", names, seed)
+
+	bySeat := map[string]*seat{}
+	for _, s := range players {
+		bySeat[s.name] = s
+	}
+
+	for !m.Finished() {
+		active := m.Active()
+		decisions, left := collectDecisions(m, active, bySeat)
+		for name, err := range left {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				fmt.Printf("%s took too long to decide and was evicted.\n", name)
+			} else {
+				fmt.Printf("%s left the match and was evicted.\n", name)
+			}
+			bySeat[name].conn.Close()
+			m.Player(name).State.Evicted = true
+		}
+		if len(decisions) == 0 {
+			continue
+		}
+
+		if err := m.Resolve(decisions); err != nil {
+			return err
+		}
+		fmt.Printf("Year %d is played.\n", m.Year-1)
+		for _, p := range active {
+			report := p.LastYear
+			_ = bySeat[p.Name].send(matchMessage{Type: msgReport, Report: &report})
+		}
+	}
+
+	standings := m.Standings()
+	PrintStandings(color.Output, standings)
+	for _, s := range players {
+		_ = s.send(matchMessage{Type: msgStandings, Standings: standings})
+	}
+	return nil
+}
+
+// acceptPlayers waits for seats players to join, turning away anyone who takes a name already taken
+func acceptPlayers(ln net.Listener, seats int) ([]*seat, error) {
+	var players []*seat
+	taken := map[string]bool{}
+	for len(players) < seats {
+		conn, err := ln.Accept()
+		if err != nil {
+			return nil, err
+		}
+		s := &seat{conn: conn, enc: json.NewEncoder(conn), dec: json.NewDecoder(conn)}
+
+		// someone who connects and never joins must not keep the others out
+		var join matchMessage
+		_ = conn.SetReadDeadline(time.Now().Add(joinTimeout))
+		if err := s.dec.Decode(&join); err != nil || join.Type != msgJoin || join.Name == "" || taken[join.Name] {
+			_ = s.send(matchMessage{Type: msgRejected, Message: Text("match.nameTaken")})
+			conn.Close()
+			continue
+		}
+		_ = conn.SetReadDeadline(time.Time{})
+		s.name = join.Name
+		taken[s.name] = true
+		players = append(players, s)
+		fmt.Printf("%s joined from %s.\n", s.name, conn.RemoteAddr())
+
+		for _, p := range players {
+			_ = p.send(matchMessage{Type: msgWait, Message: Text("match.waiting", len(players), seats)})
+		}
+	}
+	return players, nil
+}
+
+// collectDecisions sends this year's state to every active player and waits until they have
+// all decided. It returns the decisions, and the players who disconnected or ran out of time,
+// with the error that tells which.
+func collectDecisions(m *Match, active []*Player, bySeat map[string]*seat) (map[string]Decisions, map[string]error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	decisions := map[string]Decisions{}
+	left := map[string]error{}
+
+	for _, p := range active {
+		s := bySeat[p.Name]
+		state := p.State
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d, err := askSeat(m, s, state)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				left[s.name] = err
+				return
+			}
+			decisions[s.name] = d
+		}()
+	}
+	wg.Wait()
+	return decisions, left
+}
+
+// askSeat asks a player for their decisions until they send valid ones, or decisionTimeout
+// runs out
+func askSeat(m *Match, s *seat, state GameState) (Decisions, error) {
+	if err := s.send(matchMessage{Type: msgYear, State: &state}); err != nil {
+		return Decisions{}, err
+	}
+	if err := s.conn.SetReadDeadline(time.Now().Add(decisionTimeout)); err != nil {
+		return Decisions{}, err
+	}
+	defer s.conn.SetReadDeadline(time.Time{})
+	for {
+		var msg matchMessage
+		if err := s.dec.Decode(&msg); err != nil {
+			return Decisions{}, err
+		}
+		if msg.Type != msgDecisions || msg.Decisions == nil {
+			continue
+		}
+		// the match is only changed once everyone has decided, so it is safe to validate against
+		if err := m.Validate(s.name, *msg.Decisions); err != nil {
+			if err := s.send(matchMessage{Type: msgRejected, Message: jestMessage(err)}); err != nil {
+				return Decisions{}, err
+			}
+			continue
+		}
+		return *msg.Decisions, nil
+	}
+}
+
+// JoinMatch joins the match hosted on addr as name, and plays it in the terminal
+func JoinMatch(addr, name string) error {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	enc := json.NewEncoder(conn)
+	dec := json.NewDecoder(conn)
+
+	if err := enc.Encode(matchMessage{Type: msgJoin, Name: name}); err != nil {
+		return err
+	}
+
+	in := newConsole(nil, Rules{}, 0)
+	var state GameState
+	for {
+		var msg matchMessage
+		if err := dec.Decode(&msg); err != nil {
+			return fmt.Errorf("lost the connection to the host: %w", err)
+		}
+
+		switch msg.Type {
+		case msgWait:
+			fmt.Println(msg.Message)
+		case msgYear, msgRejected:
+			if msg.Type == msgYear {
+				state = *msg.State
+				clearScreen()
+				color.Green("%s", Text("match.corporation", name))
+				printSummary(state.Summary())
+			} else {
+				if state.Year == 0 {
+					return errors.New(msg.Message)
+				}
+				jest(errors.New(msg.Message))
+			}
+			d, err := askForDecisions(in, state)
+			if err != nil {
+				return err
+			}
+			if err := enc.Encode(matchMessage{Type: msgDecisions, Decisions: &d}); err != nil {
+				return err
+			}
+			fmt.Println("")
+			fmt.Println(Text("match.waitOthers"))
+		case msgReport:
+			fmt.Println("")
+			printYearEvents(*msg.Report)
+			if msg.Report.Evicted {
+				color.Red("%s", Text("match.youWereEvicted"))
+			}
+			if msg.Report.Bankrupt {
+				color.Red("%s", Text("match.youWentBankrupt"))
+			}
+			fmt.Println("")
+		case msgStandings:
+			clearScreen()
+			PrintStandings(color.Output, msg.Standings)
+			for _, st := range msg.Standings {
+				if st.Name == name {
+					fmt.Println("")
+					fmt.Println(st.Score.Message)
+				}
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package game
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// LedgerEntry records one year of a game: the prices the player decided at, what they
+// decided, and what came of it
+type LedgerEntry struct {
+	Year          int       `json:"year"`
+	ComputerPrice int       `json:"computerPrice"`
+	ExchangeRate  int       `json:"exchangeRate"`
+	Employees     int       `json:"employees"`
+	Decisions     Decisions `json:"decisions"`
+	// BuyPrice is what each computer bought cost, and SaleValue what the computers sold fetched together
+	BuyPrice  int `json:"buyPrice"`
+	SaleValue int `json:"saleValue"`
+	// Maintained is how many computers the maintenance budget kept running
+	Maintained int `json:"maintained"`
+
+	Starved               int      `json:"starved"`
+	NewEmployees          int      `json:"newEmployees"`
+	MarketCrashVictims    int      `json:"marketCrashVictims"`
+	CashMined             int      `json:"cashMined"`
+	AmountStolenByHackers int      `json:"amountStolenByHackers"`
+	ComputersFailed       int      `json:"computersFailed"`
+	InterestPaid          int      `json:"interestPaid"`
+	ComputersSeized       int      `json:"computersSeized"`
+	Events                []string `json:"events"`
+
+	EndCash      int `json:"endCash"`
+	EndFiat      int `json:"endFiat"`
+	EndComputers int `json:"endComputers"`
+	EndEmployees int `json:"endEmployees"`
+	EndDebt      int `json:"endDebt"`
+
+	// StandingBefore and StandingAfter are the computers the term would be rated on at the
+	// start and at the end of the year
+	StandingBefore int `json:"standingBefore"`
+	StandingAfter  int `json:"standingAfter"`
+}
+
+// Ledger is the history of a game, one entry per year played
+type Ledger []LedgerEntry
+
+// NewLedgerEntry records the year that took the corporation from before to after
+func NewLedgerEntry(before GameState, d Decisions, after GameState, r YearReport) LedgerEntry {
+	e := LedgerEntry{
+		Year:                  before.Year,
+		ComputerPrice:         before.ComputerPrice,
+		ExchangeRate:          before.ExchangeRate,
+		Employees:             before.Employees,
+		Decisions:             d,
+		Starved:               r.Starved,
+		NewEmployees:          r.NewEmployees,
+		MarketCrashVictims:    r.MarketCrashVictims,
+		CashMined:             r.CashMined,
+		AmountStolenByHackers: r.AmountStolenByHackers,
+		ComputersFailed:       r.ComputersFailed,
+		InterestPaid:          r.InterestPaid,
+		ComputersSeized:       r.ComputersSeized,
+		EndCash:               after.Cash,
+		EndFiat:               after.Fiat,
+		EndComputers:          after.Computers,
+		EndEmployees:          after.Employees,
+		EndDebt:               after.Debt,
+		StandingBefore:        standing(before),
+		StandingAfter:         standing(after),
+	}
+	for _, event := range r.Events {
+		e.Events = append(e.Events, event.Name)
+	}
+
+	// carry the decisions out again to find the prices they were carried out at
+	s := before
+	s.borrow(d.Borrow)
+	s.convert(d.BitcoinToSell)
+	s.choose(d.Generation)
+	g, _ := s.generation(d.Generation)
+	e.Decisions.Generation = g.Name
+	e.BuyPrice = s.priceOf(g)
+	s.buy(d.ComputersToBuy)
+	e.SaleValue = s.saleValue(d.ComputersToSell)
+	s.sell(d.ComputersToSell)
+	e.Maintained = computersMaintained(s, d.MaintenanceAmount)
+	return e
+}
+
+// ledgerHeader is the header of the CSV written by WriteCSV
+var ledgerHeader = []string{
+	"year", "computerPrice", "exchangeRate", "employees",
+	"borrow", "bitcoinToSell", "generation", "computersToBuy", "buyPrice", "computersToSell", "saleValue",
+	"cashPaidToEmployees", "maintenanceAmount", "maintained",
+	"starved", "newEmployees", "marketCrashVictims", "cashMined", "amountStolenByHackers", "computersFailed",
+	"interestPaid", "computersSeized", "events",
+	"endCash", "endFiat", "endComputers", "endEmployees", "endDebt", "standingBefore", "standingAfter",
+}
+
+// row returns the entry as a row of the CSV written by WriteCSV
+func (e LedgerEntry) row() []string {
+	var row []string
+	for _, n := range []int{e.Year, e.ComputerPrice, e.ExchangeRate, e.Employees, e.Decisions.Borrow, e.Decisions.BitcoinToSell} {
+		row = append(row, strconv.Itoa(n))
+	}
+	row = append(row, e.Decisions.Generation)
+	ints := []int{
+		e.Decisions.ComputersToBuy, e.BuyPrice, e.Decisions.ComputersToSell, e.SaleValue,
+		e.Decisions.CashPaidToEmployees, e.Decisions.MaintenanceAmount, e.Maintained,
+		e.Starved, e.NewEmployees, e.MarketCrashVictims, e.CashMined, e.AmountStolenByHackers, e.ComputersFailed,
+		e.InterestPaid, e.ComputersSeized,
+	}
+	for _, n := range ints {
+		row = append(row, strconv.Itoa(n))
+	}
+	row = append(row, strings.Join(e.Events, ";"))
+	for _, n := range []int{e.EndCash, e.EndFiat, e.EndComputers, e.EndEmployees, e.EndDebt, e.StandingBefore, e.StandingAfter} {
+		row = append(row, strconv.Itoa(n))
+	}
+	return row
+}
+
+// WriteCSV writes one CSV row per year
+func (l Ledger) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(ledgerHeader); err != nil {
+		return err
+	}
+	for _, e := range l {
+		if err := cw.Write(e.row()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the ledger as a JSON array, one object per year
+func (l Ledger) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+// Export writes the ledger to path, as JSON if the file name ends in .json and as CSV otherwise
+func (l Ledger) Export(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("exporting ledger: %w", err)
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = l.WriteJSON(f)
+	} else {
+		err = l.WriteCSV(f)
+	}
+	if err != nil {
+		return fmt.Errorf("exporting ledger: %w", err)
+	}
+	return f.Close()
+}
+
+// turningPoints returns up to n years that moved the standing the most, in the order they were
+// played. When a term ends TERRIBLE or BANKRUPT, the last year is always among them, since that
+// year alone decides the rating.
+func (l Ledger) turningPoints(n int, rating Rating) []LedgerEntry {
+	if len(l) == 0 {
+		return nil
+	}
+	years := append(Ledger(nil), l...)
+	sort.SliceStable(years, func(i, j int) bool {
+		return abs(years[i].StandingAfter-years[i].StandingBefore) > abs(years[j].StandingAfter-years[j].StandingBefore)
+	})
+	years = years[:min(n, len(years))]
+
+	if last := l[len(l)-1]; rating <= Terrible && !years.has(last.Year) {
+		years[len(years)-1] = last
+	}
+	sort.SliceStable(years, func(i, j int) bool { return years[i].Year < years[j].Year })
+	return years
+}
+
+// has reports whether the ledger has an entry for year
+func (l Ledger) has(year int) bool {
+	for _, e := range l {
+		if e.Year == year {
+			return true
+		}
+	}
+	return false
+}
+
+// describe says what the player decided in the year and what came of it
+func (e LedgerEntry) describe() string {
+	var decided []string
+	d := e.Decisions
+	switch {
+	case d.Borrow > 0:
+		decided = append(decided, Text("report.borrowed", d.Borrow))
+	case d.Borrow < 0:
+		decided = append(decided, Text("report.repaid", -d.Borrow))
+	}
+	switch {
+	case d.BitcoinToSell > 0:
+		decided = append(decided, Text("report.soldBitcoin", d.BitcoinToSell, e.ExchangeRate))
+	case d.BitcoinToSell < 0:
+		decided = append(decided, Text("report.boughtBitcoin", -d.BitcoinToSell, e.ExchangeRate))
+	}
+	if d.ComputersToBuy > 0 {
+		decided = append(decided, Text("report.boughtComputers", d.ComputersToBuy, generationName(d.Generation), e.BuyPrice))
+	}
+	if d.ComputersToSell > 0 {
+		decided = append(decided, Text("report.soldComputers", d.ComputersToSell, e.SaleValue))
+	}
+	decided = append(decided, Text("report.paid", d.CashPaidToEmployees, e.Employees))
+	decided = append(decided, Text("report.maintained", e.Maintained))
+
+	var outcome []string
+	if e.Starved > 0 {
+		outcome = append(outcome, Text("report.starved", e.Starved))
+	}
+	if e.MarketCrashVictims > 0 {
+		outcome = append(outcome, Text("report.crash", e.MarketCrashVictims))
+	}
+	outcome = append(outcome, Text("report.mined", e.CashMined))
+	if e.AmountStolenByHackers > 0 {
+		outcome = append(outcome, Text("report.stolen", e.AmountStolenByHackers))
+	}
+	if e.ComputersFailed > 0 {
+		outcome = append(outcome, Text("report.failed", e.ComputersFailed))
+	}
+	if e.InterestPaid > 0 {
+		outcome = append(outcome, Text("report.interest", e.InterestPaid))
+	}
+	if e.ComputersSeized > 0 {
+		outcome = append(outcome, Text("report.seized", e.ComputersSeized))
+	}
+
+	return Text("report.decided", strings.Join(decided, ", "), strings.Join(outcome, ", "))
+}
+
+// WriteReport writes a plain-text report of a finished game: the year by year ledger, the
+// trajectory of the corporation, and the years that did the most for or against the rating
+func WriteReport(w io.Writer, l Ledger, final GameState) {
+	if len(l) == 0 {
+		return
+	}
+	rating := FinalRating(final)
+
+	fmt.Fprintln(w, Text("report.title"))
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, Text("report.columns"))
+	for _, e := range l {
+		d := e.Decisions
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t\n",
+			e.Year, e.ComputerPrice, e.ExchangeRate,
+			d.BitcoinToSell, d.ComputersToBuy, d.ComputersToSell, d.CashPaidToEmployees, d.MaintenanceAmount,
+			e.Starved, e.NewEmployees, e.MarketCrashVictims, e.CashMined, e.AmountStolenByHackers,
+			e.EndCash, e.EndComputers, e.EndEmployees)
+	}
+	tw.Flush()
+
+	var cash, computers, employees, standings []int
+	for _, e := range l {
+		cash = append(cash, e.EndCash)
+		computers = append(computers, e.EndComputers)
+		employees = append(employees, e.EndEmployees)
+		standings = append(standings, e.StandingAfter)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, Text("report.cash", sparkline(cash), l[0].EndCash, l[len(l)-1].EndCash))
+	fmt.Fprintln(w, Text("report.computers", sparkline(computers), l[0].EndComputers, l[len(l)-1].EndComputers))
+	fmt.Fprintln(w, Text("report.employees", sparkline(employees), l[0].EndEmployees, l[len(l)-1].EndEmployees))
+	fmt.Fprintln(w, Text("report.standing", sparkline(standings), l[0].StandingBefore, l[len(l)-1].StandingAfter))
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, Text("report.turningPoints", rating))
+	for _, e := range l.turningPoints(3, rating) {
+		fmt.Fprintln(w, Text("report.year", e.Year, e.StandingAfter-e.StandingBefore, e.describe()))
+	}
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,177 @@
+package game
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale is the language the game falls back to for any message a catalog leaves out
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalog is the text of the game in one language, by message ID
+type catalog struct {
+	Locale   string             `json:"locale"`
+	Messages map[string]message `json:"messages"`
+}
+
+// message is the text of one message. A message that counts something has a form for
+// every plural category its language uses; any other message only has Other.
+type message struct {
+	One   string `json:"one"`
+	Other string `json:"other"`
+}
+
+// UnmarshalJSON reads a message written either as a plain string or as an object of plural forms
+func (m *message) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = message{Other: text}
+		return nil
+	}
+
+	type forms message
+	if err := json.Unmarshal(data, (*forms)(m)); err != nil {
+		return err
+	}
+	if m.Other == "" {
+		return fmt.Errorf("a plural message needs an \"other\" form")
+	}
+	return nil
+}
+
+// form returns the text of the message for the plural category of n
+func (m message) form(n int) string {
+	if pluralCategory(n) == "one" && m.One != "" {
+		return m.One
+	}
+	return m.Other
+}
+
+// pluralCategory returns the CLDR plural category of the cardinal n. Only languages that tell
+// one from other, such as English and German, are supported; a catalog in a language with
+// more forms needs its rules here, by locale, and its forms in message.
+func pluralCategory(n int) string {
+	if n == 1 || n == -1 {
+		return "one"
+	}
+	return "other"
+}
+
+// catalogs holds every catalog shipped with the game, by locale
+var catalogs = map[string]*catalog{}
+
+// active is the catalog the game talks to the player in
+var active *catalog
+
+func init() {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var c catalog
+		if err := json.Unmarshal(data, &c); err != nil {
+			panic(fmt.Sprintf("locale %s: %v", entry.Name(), err))
+		}
+		catalogs[c.Locale] = &c
+	}
+	active = catalogs[DefaultLocale]
+}
+
+// Locales returns the locales the game ships a catalog for, in alphabetical order
+func Locales() []string {
+	var locales []string
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// SetLocale switches the game to locale, such as "de" or "de_DE.UTF-8". An empty locale
+// is taken from the environment, and a locale with no catalog falls back to English.
+// It returns the locale the game now talks in.
+func SetLocale(locale string) (string, error) {
+	if locale == "" {
+		locale = LocaleFromEnv()
+	}
+	lang := baseLanguage(locale)
+	c, ok := catalogs[lang]
+	if !ok {
+		active = catalogs[DefaultLocale]
+		if locale == "" || lang == "c" || lang == "posix" {
+			return DefaultLocale, nil
+		}
+		return DefaultLocale, fmt.Errorf("no catalog for locale %q, expected one of %v", locale, Locales())
+	}
+	active = c
+	return lang, nil
+}
+
+// LocaleFromEnv returns the locale set in the environment, by the variables in the order POSIX reads them
+func LocaleFromEnv() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(env); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// baseLanguage returns the language of a locale like "de_DE.UTF-8", in lower case
+func baseLanguage(locale string) string {
+	lang, _, _ := strings.Cut(locale, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	lang, _, _ = strings.Cut(lang, "_")
+	lang, _, _ = strings.Cut(lang, "-")
+	return strings.ToLower(lang)
+}
+
+// lookup returns the message called id in the active catalog, or in English if the active
+// catalog leaves it out
+func lookup(id string) message {
+	if m, ok := active.Messages[id]; ok {
+		return m
+	}
+	if m, ok := catalogs[DefaultLocale].Messages[id]; ok {
+		return m
+	}
+	return message{Other: id}
+}
+
+// Text returns the message called id in the active locale, formatted with args
+func Text(id string, args ...any) string {
+	return format(lookup(id).Other, args)
+}
+
+// Plural returns the message called id in the form for n, formatted with args. n is not
+// an argument unless it is passed in args too.
+func Plural(id string, n int, args ...any) string {
+	return format(lookup(id).form(n), args)
+}
+
+// format formats text with args, unless there are none, so that a message without
+// arguments may contain a literal percent sign
+func format(text string, args []any) string {
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// title is how the game addresses the player
+func title() string {
+	return Text("title")
+}
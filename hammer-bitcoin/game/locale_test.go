@@ -0,0 +1,68 @@
+package game
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestCatalogs checks that every catalog has every message of the English one, and no other
+func TestCatalogs(t *testing.T) {
+	english := catalogs[DefaultLocale]
+	for locale, c := range catalogs {
+		for id, m := range english.Messages {
+			translated, ok := c.Messages[id]
+			if !ok {
+				t.Errorf("%s: message %s is missing", locale, id)
+				continue
+			}
+			if (m.One == "") != (translated.One == "") {
+				t.Errorf("%s: message %s counts in one language but not in the other", locale, id)
+			}
+		}
+		for id := range c.Messages {
+			if _, ok := english.Messages[id]; !ok {
+				t.Errorf("%s: message %s is not in the %s catalog", locale, id, DefaultLocale)
+			}
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+
+	tests := []struct {
+		locale string
+		n      int
+		want   string
+	}{
+		{"en", 1, "1 employee starved to death."},
+		{"en", 0, "0 employees starved to death."},
+		{"en", 2, "2 employees starved to death."},
+		{"de", 1, "1 Angestellter ist verhungert."},
+		{"de", 2, "2 Angestellte sind verhungert."},
+	}
+	for _, tt := range tests {
+		if _, err := SetLocale(tt.locale); err != nil {
+			t.Fatal(err)
+		}
+		if got := Plural("year.starved", tt.n, tt.n); got != tt.want {
+			t.Errorf("%s, %d: got %q, want %q", tt.locale, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPrintScoresLocale(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+
+	for locale, want := range map[string]string{"en": "No scores yet.", "de": "Noch keine Ergebnisse."} {
+		if _, err := SetLocale(locale); err != nil {
+			t.Fatal(err)
+		}
+		var b bytes.Buffer
+		PrintScores(&b, nil)
+		if got := strings.TrimSpace(b.String()); got != want {
+			t.Errorf("%s: got %q, want %q", locale, got, want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package game
+
+import "math/rand/v2"
+
+// updateExchangeRate moves the price of a bitcoin in dollars for the next year. The rate
+// is pulled part of the way back towards its long-run mean, then shaken by a random move
+// of up to the rules' volatility in either direction. It never drops below 1 dollar.
+func updateExchangeRate(rng *rand.Rand, rules Rules, rate int) int {
+	next := rate + ((rules.ExchangeRateMean-rate)*rules.ExchangeRateReversion)/100
+
+	volatility := rules.ExchangeRateVolatility
+	move := rng.IntN(2*volatility+1) - volatility
+	next = next + (next*move)/100
+
+	return max(next, 1)
+}
+
+// convert sells bitcoinToSell bitcoins for dollars at the current exchange rate.
+// A negative amount buys bitcoins with dollars instead.
+func (s *GameState) convert(bitcoinToSell int) {
+	s.Cash = s.Cash - bitcoinToSell
+	s.Fiat = s.Fiat + bitcoinToSell*s.ExchangeRate
+}
+
+// NetWorth returns what the corporation's bitcoin and dollars are worth together, less what
+// it owes the bank, in bitcoin
+func (s GameState) NetWorth() int {
+	return s.Cash + s.Fiat/s.ExchangeRate - s.Debt
+}
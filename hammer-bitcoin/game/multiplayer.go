@@ -0,0 +1,222 @@
+package game
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sort"
+	"text/tabwriter"
+)
+
+// MinPlayers and MaxPlayers bound how many corporations can share a market
+const (
+	MinPlayers = 2
+	MaxPlayers = 6
+)
+
+// The shared computer market. The price is pulled back towards marketMean each year, moves
+// marketImpact percent for every percent of all the players' computers bought on balance,
+// and drifts up to marketNoise bitcoins either way.
+const (
+	marketMean      = 21
+	marketReversion = 20
+	marketImpact    = 2
+	marketMaxMove   = 50
+	marketNoise     = 2
+)
+
+// Player is one corporation in a match
+type Player struct {
+	Name  string    `json:"name"`
+	State GameState `json:"state"`
+	// LastYear is what happened to the corporation in the year just played
+	LastYear YearReport `json:"lastYear"`
+}
+
+// Match is a multiplayer game: every player runs their own corporation, with its own
+// employees, cash and random events, but they all buy and sell computers and bitcoin on one
+// shared market. A year is played once every player still in office has decided.
+type Match struct {
+	Rules   Rules     `json:"rules"`
+	Seed    uint64    `json:"seed"`
+	Year    int       `json:"year"`
+	Players []*Player `json:"players"`
+	// ComputerPrice and ExchangeRate are the market prices everyone trades at this year
+	ComputerPrice int `json:"computerPrice"`
+	ExchangeRate  int `json:"exchangeRate"`
+
+	rng *rand.Rand
+}
+
+// Standing is a player's place at the end of a match
+type Standing struct {
+	Rank  int        `json:"rank"`
+	Name  string     `json:"name"`
+	Score FinalScore `json:"score"`
+}
+
+// NewMatch starts a match played by rules between the named players
+func NewMatch(rules Rules, seed uint64, names []string) (*Match, error) {
+	if len(names) < MinPlayers || len(names) > MaxPlayers {
+		return nil, fmt.Errorf("a match needs %d to %d players, not %d", MinPlayers, MaxPlayers, len(names))
+	}
+	seen := map[string]bool{}
+	for _, name := range names {
+		if name == "" {
+			return nil, fmt.Errorf("every player needs a name")
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("two players are called %s", name)
+		}
+		seen[name] = true
+	}
+
+	m := &Match{
+		Rules: rules,
+		Seed:  seed,
+		Year:  1,
+		rng:   NewRand(seed),
+	}
+	m.ComputerPrice = updateComputerPrice(m.rng)
+	m.ExchangeRate = rules.StartingExchangeRate
+	for _, name := range names {
+		state := NewGame(rules, m.rng)
+		state.ComputerPrice = m.ComputerPrice
+		m.Players = append(m.Players, &Player{Name: name, State: state})
+	}
+	return m, nil
+}
+
+// Finished reports whether every player's term is over
+func (m *Match) Finished() bool {
+	for _, p := range m.Players {
+		if !p.State.Finished() {
+			return false
+		}
+	}
+	return true
+}
+
+// Active returns the players still in office, who have to decide this year
+func (m *Match) Active() []*Player {
+	var active []*Player
+	for _, p := range m.Players {
+		if !p.State.Finished() {
+			active = append(active, p)
+		}
+	}
+	return active
+}
+
+// Player returns the player called name, or nil
+func (m *Match) Player(name string) *Player {
+	for _, p := range m.Players {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Validate checks a player's decisions for this year
+func (m *Match) Validate(name string, d Decisions) error {
+	p := m.Player(name)
+	if p == nil {
+		return fmt.Errorf("no player is called %s", name)
+	}
+	if p.State.Finished() {
+		return fmt.Errorf("%s is no longer in office", name)
+	}
+	return ValidateDecisions(p.State, d)
+}
+
+// Resolve plays the year with the decisions of every active player, by name. Nothing is
+// played unless every active player's decisions are there and valid.
+func (m *Match) Resolve(decisions map[string]Decisions) error {
+	active := m.Active()
+	for _, p := range active {
+		d, ok := decisions[p.Name]
+		if !ok {
+			return fmt.Errorf("%s has not decided yet", p.Name)
+		}
+		if err := m.Validate(p.Name, d); err != nil {
+			return fmt.Errorf("%s: %w", p.Name, err)
+		}
+	}
+
+	// every corporation runs its own year, with its own events
+	netVolume, computers := 0, 0
+	for _, p := range active {
+		d := decisions[p.Name]
+		p.State, p.LastYear = operate(p.State, d, m.rng)
+		netVolume = netVolume + d.ComputersToBuy - d.ComputersToSell
+		computers = computers + p.State.Computers
+	}
+
+	// then the market sets next year's prices for everyone
+	market := GameState{
+		Rules:         m.Rules,
+		ComputerPrice: moveComputerPrice(m.rng, m.ComputerPrice, netVolume, computers),
+		ExchangeRate:  updateExchangeRate(m.rng, m.Rules, m.ExchangeRate),
+	}
+	var marketReport YearReport
+	rollEvents(NewPrices, &market, &marketReport, m.rng)
+	m.ComputerPrice = market.ComputerPrice
+	m.ExchangeRate = market.ExchangeRate
+
+	for _, p := range active {
+		p.State.ComputerPrice = m.ComputerPrice
+		p.State.ExchangeRate = m.ExchangeRate
+		p.LastYear.Events = append(p.LastYear.Events, marketReport.Events...)
+		endYear(&p.State, &p.LastYear)
+	}
+	m.Year++
+	return nil
+}
+
+// moveComputerPrice returns next year's price of a computer on the shared market, where
+// netVolume computers were bought on balance out of the computers all the players own
+func moveComputerPrice(rng *rand.Rand, price, netVolume, computers int) int {
+	move := 0
+	if computers > 0 {
+		move = marketImpact * 100 * netVolume / computers
+	}
+	move = min(max(move, -marketMaxMove), marketMaxMove)
+
+	next := price + (marketMean-price)*marketReversion/100 + price*move/100
+	next = next + rng.IntN(2*marketNoise+1) - marketNoise
+	return max(next, 1)
+}
+
+// Standings ranks the players by their final score: by rating, then by computers, then by cash
+func (m *Match) Standings() []Standing {
+	var standings []Standing
+	for _, p := range m.Players {
+		standings = append(standings, Standing{Name: p.Name, Score: NewFinalScore(p.State)})
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i].Score, standings[j].Score
+		if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+		if a.Computers != b.Computers {
+			return a.Computers > b.Computers
+		}
+		return a.Cash > b.Cash
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+	return standings
+}
+
+// PrintStandings prints the final standings of a match as a table
+func PrintStandings(w io.Writer, standings []Standing) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, Text("match.columns"))
+	for _, st := range standings {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%d\t%d\t\n",
+			st.Rank, st.Name, st.Score.Rating, st.Score.Computers, st.Score.Employees, st.Score.Cash, st.Score.Fiat)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,53 @@
+package game
+
+import "fmt"
+
+// replayVersion is the version of the replay file format written by Save. It goes up whenever
+// the game asks different questions or plays them out differently, since the answers of an
+// older replay would then be given to the wrong questions.
+const replayVersion = 6
+
+// Replay holds everything needed to play a game again exactly: the rules, the seed of the
+// random number generator and every number the player entered, in order. The random events
+// that happened are logged too, although playing the replay rolls them again.
+type Replay struct {
+	Version int            `json:"version"`
+	Rules   Rules          `json:"rules"`
+	Seed    uint64         `json:"seed"`
+	Answers []int          `json:"answers"`
+	Events  []EventOutcome `json:"events"`
+}
+
+// NewReplay returns an empty replay for a game played by rules and started with seed
+func NewReplay(rules Rules, seed uint64) *Replay {
+	return &Replay{
+		Version: replayVersion,
+		Rules:   rules,
+		Seed:    seed,
+		Answers: []int{},
+		Events:  []EventOutcome{},
+	}
+}
+
+// LoadReplay reads a replay file written by Save
+func LoadReplay(path string) (*Replay, error) {
+	var r Replay
+	if err := readJSONFile(path, &r); err != nil {
+		return nil, fmt.Errorf("reading replay: %w", err)
+	}
+	if r.Version != replayVersion {
+		return nil, fmt.Errorf("reading replay %s: %w", path, versionError(r.Version, replayVersion))
+	}
+	if err := r.Rules.Validate(); err != nil {
+		return nil, fmt.Errorf("reading replay %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Save writes the replay to path as JSON
+func (r *Replay) Save(path string) error {
+	if err := writeJSONFile(path, r); err != nil {
+		return fmt.Errorf("saving replay: %w", err)
+	}
+	return nil
+}
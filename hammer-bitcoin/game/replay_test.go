@@ -0,0 +1,124 @@
+package game
+
+import (
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// playedGame is a game played by a strategy, and the answers a player typing its decisions
+// at the console would have given
+type playedGame struct {
+	answers []int
+	ledger  Ledger
+	// years are the answers given in each year, in the order of the years
+	years [][]int
+}
+
+// playStrategy plays a whole term by rules with st, started with seed
+func playStrategy(t *testing.T, st Strategy, rules Rules, seed uint64) playedGame {
+	t.Helper()
+	var g playedGame
+	rng := NewRand(seed)
+	s := NewGame(rules, rng)
+	for !s.Finished() {
+		d := st.Decide(s.Summary())
+		if err := ValidateDecisions(s, d); err != nil {
+			t.Fatalf("%s in year %d: %v", st.Name(), s.Year, err)
+		}
+		year := consoleAnswers(s, d)
+		g.answers = append(g.answers, year...)
+		g.years = append(g.years, year)
+
+		before := s
+		var r YearReport
+		s, r = Step(s, d, rng)
+		g.ledger = append(g.ledger, NewLedgerEntry(before, d, s, r))
+	}
+	return g
+}
+
+// consoleAnswers returns the answers to the questions askForDecisions asks, in order, that
+// make the decisions d in a year that starts at s
+func consoleAnswers(s GameState, d Decisions) []int {
+	offer := 1
+	for _, o := range s.Catalog() {
+		if o.Generation == d.Generation {
+			offer = o.Number
+		}
+	}
+	return []int{d.Borrow, d.BitcoinToSell, offer, d.ComputersToBuy, d.ComputersToSell, d.CashPaidToEmployees, d.MaintenanceAmount}
+}
+
+// answerLines returns answers as the lines a player would type them on
+func answerLines(answers []int) string {
+	var lines strings.Builder
+	for _, a := range answers {
+		lines.WriteString(strconv.Itoa(a) + "\n")
+	}
+	return lines.String()
+}
+
+// checkPlayed checks that the ledger and the replay Play wrote to dir are those of want
+func checkPlayed(t *testing.T, dir string, want playedGame) {
+	t.Helper()
+	var ledger Ledger
+	if err := readJSONFile(filepath.Join(dir, "ledger.json"), &ledger); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ledger, want.ledger) {
+		t.Errorf("got ledger\n%+v\nwant\n%+v", ledger, want.ledger)
+	}
+
+	recorded, err := LoadReplay(filepath.Join(dir, "recorded.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(recorded.Answers, want.answers) {
+		t.Errorf("recorded answers %v, want %v", recorded.Answers, want.answers)
+	}
+}
+
+// TestReplay plays a recorded game back from its replay file, and checks that every year
+// comes out the same
+func TestReplay(t *testing.T) {
+	SetInput(strings.NewReader(""))
+	const seed = 7
+	want := playStrategy(t, Conservative{}, DefaultRules, seed)
+
+	dir := t.TempDir()
+	replay := NewReplay(DefaultRules, seed)
+	replay.Answers = want.answers
+	if err := replay.Save(filepath.Join(dir, "replay.json")); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadReplay(filepath.Join(dir, "replay.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Play(Config{
+		Replay:     loaded,
+		RecordFile: filepath.Join(dir, "recorded.json"),
+		LedgerFile: filepath.Join(dir, "ledger.json"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlayed(t, dir, want)
+}
+
+func TestLoadReplayVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replay.json")
+	replay := NewReplay(DefaultRules, 1)
+	replay.Version = replayVersion - 1
+	if err := replay.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReplay(path); err == nil || !strings.Contains(err.Error(), "start a new game") {
+		t.Errorf("got error %v, want an older version to be turned down", err)
+	}
+}
@@ -0,0 +1,418 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rules holds the numbers that decide how a game plays: what the corporation starts with,
+// what it costs to run, and which random events can happen
+type Rules struct {
+	// Name is the difficulty preset the rules are, or "custom" for rules loaded from a file
+	// that does not name them
+	Name string `json:"name"`
+
+	StartingCash      int `json:"startingCash"`
+	StartingComputers int `json:"startingComputers"`
+	StartingEmployees int `json:"startingEmployees"`
+	// TermYears is how long the player stays in office if they are not evicted
+	TermYears int `json:"termYears"`
+
+	// LivingCost is the bitcoin each employee needs per year to survive
+	LivingCost int `json:"livingCost"`
+	// ComputersPerEmployee is how many computers one employee can maintain
+	ComputersPerEmployee int `json:"computersPerEmployee"`
+	// EvictionThreshold is the percentage of employees starving in a year that gets the player evicted
+	EvictionThreshold int `json:"evictionThreshold"`
+
+	// StartingFiat is the dollars the corporation starts with
+	StartingFiat int `json:"startingFiat"`
+	// StartingExchangeRate is the price of a bitcoin in dollars in the first year
+	StartingExchangeRate int `json:"startingExchangeRate"`
+	// ExchangeRateMean is the price of a bitcoin the market drifts back to over time
+	ExchangeRateMean int `json:"exchangeRateMean"`
+	// ExchangeRateReversion is the percentage of the gap to the mean closed each year
+	ExchangeRateReversion int `json:"exchangeRateReversion"`
+	// ExchangeRateVolatility is the largest random move in the price of a bitcoin in a year, in percent
+	ExchangeRateVolatility int `json:"exchangeRateVolatility"`
+
+	// YieldMin and YieldMax bound the bitcoins mined per maintained computer
+	YieldMin int `json:"yieldMin"`
+	YieldMax int `json:"yieldMax"`
+
+	// Generations are the computers on sale, in the order they are listed in the catalog.
+	// The corporation starts with computers of the first.
+	Generations []Generation `json:"generations"`
+	// Depreciation is the percentage of its price new a computer loses every year
+	Depreciation int `json:"depreciation"`
+
+	// InterestRate is the percentage of the debt the bank charges every year
+	InterestRate int `json:"interestRate"`
+	// CreditLimit is how much the bank lends against the computers, in percent of what they would sell for
+	CreditLimit int `json:"creditLimit"`
+
+	// Events are the random events that can happen, rolled in this order within their phase
+	Events []EventConfig `json:"events"`
+}
+
+// DefaultRules are the rules of the original game
+var DefaultRules = Rules{
+	Name:                   "normal",
+	StartingCash:           2800,
+	StartingComputers:      1000,
+	StartingEmployees:      100,
+	TermYears:              10,
+	LivingCost:             20,
+	ComputersPerEmployee:   10,
+	EvictionThreshold:      45,
+	StartingFiat:           0,
+	StartingExchangeRate:   100,
+	ExchangeRateMean:       100,
+	ExchangeRateReversion:  20,
+	ExchangeRateVolatility: 25,
+	YieldMin:               1,
+	YieldMax:               6,
+	Generations:            DefaultGenerations,
+	Depreciation:           15,
+	InterestRate:           8,
+	CreditLimit:            50,
+	Events: []EventConfig{
+		{Name: "crash", Chance: 15, Min: 50, Max: 50},
+		{Name: "hackers", Chance: 40, Min: 10, Max: 30},
+	},
+}
+
+// Presets are the built-in difficulty levels, by name
+var Presets = map[string]Rules{
+	"easy": {
+		Name:                   "easy",
+		StartingCash:           4000,
+		StartingComputers:      1000,
+		StartingEmployees:      100,
+		TermYears:              10,
+		LivingCost:             20,
+		ComputersPerEmployee:   10,
+		EvictionThreshold:      55,
+		StartingFiat:           0,
+		StartingExchangeRate:   100,
+		ExchangeRateMean:       100,
+		ExchangeRateReversion:  30,
+		ExchangeRateVolatility: 15,
+		YieldMin:               2,
+		YieldMax:               7,
+		Generations:            DefaultGenerations,
+		Depreciation:           10,
+		InterestRate:           5,
+		CreditLimit:            60,
+		Events: []EventConfig{
+			{Name: "crash", Chance: 10, Min: 50, Max: 50},
+			{Name: "hackers", Chance: 30, Min: 5, Max: 20},
+			{Name: "bull-run", Chance: 10, Min: 10, Max: 30},
+		},
+	},
+	"normal": DefaultRules,
+	"hard": {
+		Name:                   "hard",
+		StartingCash:           2800,
+		StartingComputers:      900,
+		StartingEmployees:      100,
+		TermYears:              10,
+		LivingCost:             22,
+		ComputersPerEmployee:   9,
+		EvictionThreshold:      40,
+		StartingFiat:           0,
+		StartingExchangeRate:   100,
+		ExchangeRateMean:       100,
+		ExchangeRateReversion:  10,
+		ExchangeRateVolatility: 40,
+		YieldMin:               1,
+		YieldMax:               5,
+		Generations:            DefaultGenerations,
+		Depreciation:           20,
+		InterestRate:           12,
+		CreditLimit:            40,
+		Events: []EventConfig{
+			{Name: "crash", Chance: 20, Min: 50, Max: 50},
+			{Name: "staff-strike", Chance: 10, Min: 20, Max: 50},
+			{Name: "hackers", Chance: 45, Min: 15, Max: 35},
+			{Name: "hardware-failure", Chance: 10, Min: 5, Max: 15},
+			{Name: "regulatory-fine", Chance: 5, Min: 10, Max: 20},
+		},
+	},
+}
+
+// PresetNames returns the names of the built-in presets in alphabetical order
+func PresetNames() []string {
+	var names []string
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Preset returns the built-in rules called name
+func Preset(name string) (Rules, error) {
+	rules, ok := Presets[name]
+	if !ok {
+		return Rules{}, fmt.Errorf("unknown difficulty %q, expected one of %v", name, PresetNames())
+	}
+	return rules.Clone(), nil
+}
+
+// difficulty returns the name of the preset the rules are, or "custom" when they differ from
+// every preset, even if they carry the name of one
+func (r Rules) difficulty() string {
+	if preset, ok := Presets[r.Name]; ok && reflect.DeepEqual(r, preset) {
+		return r.Name
+	}
+	return "custom"
+}
+
+// Clone returns a copy of the rules that shares nothing with r
+func (r Rules) Clone() Rules {
+	r.Events = append([]EventConfig(nil), r.Events...)
+	r.Generations = append([]Generation(nil), r.Generations...)
+	return r
+}
+
+// LoadRules reads a JSON rules file, or a YAML one if its name ends in .yaml or .yml. Any rule
+// the file leaves out keeps its value from base, so a file only needs to list what it changes.
+func LoadRules(path string, base Rules) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, fmt.Errorf("loading rules: %w", err)
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		if data, err = yamlToJSON(data); err != nil {
+			return Rules{}, fmt.Errorf("loading rules %s: %w", path, err)
+		}
+	}
+
+	// the rules are no longer those of base, unless the file names them itself
+	rules := base.Clone()
+	rules.Name = "custom"
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rules); err != nil {
+		return Rules{}, fmt.Errorf("loading rules %s: %w", path, err)
+	}
+	if err := rules.Validate(); err != nil {
+		return Rules{}, fmt.Errorf("loading rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// yamlToJSON converts a YAML document to JSON, so that it is read by the same names and
+// checked as strictly as a JSON rules file
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	return json.Marshal(doc)
+}
+
+// Validate reports the first rule that makes the game impossible to play
+func (r Rules) Validate() error {
+	if r.StartingCash < 0 {
+		return fmt.Errorf("startingCash cannot be negative, not %d", r.StartingCash)
+	}
+	if r.StartingComputers < 0 {
+		return fmt.Errorf("startingComputers cannot be negative, not %d", r.StartingComputers)
+	}
+	if r.StartingEmployees <= 0 {
+		return fmt.Errorf("startingEmployees must be at least 1, not %d", r.StartingEmployees)
+	}
+	if r.TermYears <= 0 {
+		return fmt.Errorf("termYears must be at least 1, not %d", r.TermYears)
+	}
+	if r.LivingCost <= 0 {
+		return fmt.Errorf("livingCost must be at least 1, not %d", r.LivingCost)
+	}
+	if r.ComputersPerEmployee <= 0 {
+		return fmt.Errorf("computersPerEmployee must be at least 1, not %d", r.ComputersPerEmployee)
+	}
+	if r.EvictionThreshold <= 0 || r.EvictionThreshold > 100 {
+		return fmt.Errorf("evictionThreshold must be between 1 and 100, not %d", r.EvictionThreshold)
+	}
+	if r.StartingFiat < 0 {
+		return fmt.Errorf("startingFiat cannot be negative, not %d", r.StartingFiat)
+	}
+	if r.StartingExchangeRate <= 0 {
+		return fmt.Errorf("startingExchangeRate must be at least 1, not %d", r.StartingExchangeRate)
+	}
+	if r.ExchangeRateMean <= 0 {
+		return fmt.Errorf("exchangeRateMean must be at least 1, not %d", r.ExchangeRateMean)
+	}
+	if r.ExchangeRateReversion < 0 || r.ExchangeRateReversion > 100 {
+		return fmt.Errorf("exchangeRateReversion must be between 0 and 100, not %d", r.ExchangeRateReversion)
+	}
+	if r.ExchangeRateVolatility < 0 || r.ExchangeRateVolatility > 100 {
+		return fmt.Errorf("exchangeRateVolatility must be between 0 and 100, not %d", r.ExchangeRateVolatility)
+	}
+	if r.YieldMin < 0 {
+		return fmt.Errorf("yieldMin cannot be negative, not %d", r.YieldMin)
+	}
+	if r.YieldMin > r.YieldMax {
+		return errors.New("yieldMin is larger than yieldMax")
+	}
+	if r.Depreciation < 0 || r.Depreciation > 100 {
+		return fmt.Errorf("depreciation must be between 0 and 100, not %d", r.Depreciation)
+	}
+	if r.InterestRate < 0 || r.InterestRate > 100 {
+		return fmt.Errorf("interestRate must be between 0 and 100, not %d", r.InterestRate)
+	}
+	if r.CreditLimit < 0 || r.CreditLimit > 100 {
+		return fmt.Errorf("creditLimit must be between 0 and 100, not %d", r.CreditLimit)
+	}
+	if err := validateGenerations(r.Generations); err != nil {
+		return err
+	}
+
+	// the rules must leave a way through the first year: the staff has to be able to look
+	// after the starting computers, and the corporation to feed enough of them to stay in office
+	if staffed := r.ComputersPerEmployee * r.StartingEmployees; r.StartingComputers > staffed {
+		return fmt.Errorf("startingComputers cannot be more than the %d computers the startingEmployees can maintain, not %d", staffed, r.StartingComputers)
+	}
+	means := r.startingMeans()
+	if _, starving := countStarvedEmployees(r.StartingEmployees, means, r.LivingCost); starving >= r.EvictionThreshold {
+		return fmt.Errorf("the starting cash, dollars and computers can feed only %d of the %d startingEmployees at a livingCost of %d, which gets the player evicted in the first year", means/r.LivingCost, r.StartingEmployees, r.LivingCost)
+	}
+
+	declared := map[string]bool{}
+	for _, cfg := range r.Events {
+		if err := validateEvent(cfg); err != nil {
+			return err
+		}
+		if declared[cfg.Name] {
+			return fmt.Errorf("event %s is declared more than once", cfg.Name)
+		}
+		declared[cfg.Name] = true
+	}
+	return nil
+}
+
+// startingMeans returns the least bitcoin the corporation can raise in its first year: its
+// cash, plus its dollars and computers sold at the lowest price they can fetch
+func (r Rules) startingMeans() int {
+	computer := max(minComputerPrice*r.Generations[0].Price/100, 1)
+	return r.StartingCash + r.StartingFiat/r.StartingExchangeRate + r.StartingComputers*computer
+}
+
+// RuleParams lists the names Set accepts besides the event params, in the order they are reported.
+// Each declared event also has the params NAME.chance, NAME.min and NAME.max, and the
+// original events can still be set as crash, hack, hackmin and hackmax.
+var RuleParams = []string{
+	"cash", "computers", "employees", "term", "living", "staffing", "eviction",
+	"fiat", "rate", "ratemean", "reversion", "volatility", "yieldmin", "yieldmax", "depreciation",
+	"interest", "credit",
+}
+
+// EventParams returns the params of the events declared in the rules
+func (r Rules) EventParams() []string {
+	var params []string
+	for _, cfg := range r.Events {
+		params = append(params, cfg.Name+".chance", cfg.Name+".min", cfg.Name+".max")
+	}
+	return params
+}
+
+// eventAliases maps the params of the original game's events to their event params
+var eventAliases = map[string]string{
+	"crash":   "crash.chance",
+	"hack":    "hackers.chance",
+	"hackmin": "hackers.min",
+	"hackmax": "hackers.max",
+}
+
+// field returns a pointer to the rule called param, or nil if there is no such rule
+func (r *Rules) field(param string) *int {
+	if alias, ok := eventAliases[param]; ok {
+		param = alias
+	}
+	if name, setting, ok := strings.Cut(param, "."); ok {
+		for i := range r.Events {
+			if r.Events[i].Name != name {
+				continue
+			}
+			switch setting {
+			case "chance":
+				return &r.Events[i].Chance
+			case "min":
+				return &r.Events[i].Min
+			case "max":
+				return &r.Events[i].Max
+			}
+		}
+		return nil
+	}
+
+	switch param {
+	case "cash":
+		return &r.StartingCash
+	case "computers":
+		return &r.StartingComputers
+	case "employees":
+		return &r.StartingEmployees
+	case "term":
+		return &r.TermYears
+	case "living":
+		return &r.LivingCost
+	case "staffing":
+		return &r.ComputersPerEmployee
+	case "eviction":
+		return &r.EvictionThreshold
+	case "fiat":
+		return &r.StartingFiat
+	case "rate":
+		return &r.StartingExchangeRate
+	case "ratemean":
+		return &r.ExchangeRateMean
+	case "reversion":
+		return &r.ExchangeRateReversion
+	case "volatility":
+		return &r.ExchangeRateVolatility
+	case "yieldmin":
+		return &r.YieldMin
+	case "yieldmax":
+		return &r.YieldMax
+	case "depreciation":
+		return &r.Depreciation
+	case "interest":
+		return &r.InterestRate
+	case "credit":
+		return &r.CreditLimit
+	}
+	return nil
+}
+
+// Set changes the rule called param to v
+func (r *Rules) Set(param string, v int) error {
+	f := r.field(param)
+	if f == nil {
+		return fmt.Errorf("unknown rule %q, or its event is not declared", param)
+	}
+	*f = v
+	return nil
+}
+
+// Get returns the rule called param
+func (r Rules) Get(param string) int {
+	if f := r.field(param); f != nil {
+		return *f
+	}
+	return 0
+}
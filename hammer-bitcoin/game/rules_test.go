@@ -0,0 +1,49 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRules(t *testing.T) {
+	tests := []struct {
+		name, file, data string
+		wantName         string
+		wantCash         int
+		wantErr          string
+	}{
+		{name: "changed", file: "rich.json", data: `{"startingCash": 28000}`, wantName: "custom", wantCash: 28000},
+		{name: "unchanged", file: "empty.json", data: `{}`, wantName: "custom", wantCash: DefaultRules.StartingCash},
+		{name: "named", file: "named.json", data: `{"name": "rich", "startingCash": 28000}`, wantName: "rich", wantCash: 28000},
+		{name: "yaml", file: "rich.yaml", data: "startingCash: 28000\n", wantName: "custom", wantCash: 28000},
+		{name: "empty yaml", file: "empty.yml", data: "", wantName: "custom", wantCash: DefaultRules.StartingCash},
+		{name: "unknown rule", file: "typo.yaml", data: "startingGold: 1\n", wantErr: `unknown field "startingGold"`},
+		{name: "computers without staff", file: "unstaffed.json", data: `{"startingComputers": 100000}`,
+			wantErr: "startingComputers cannot be more than"},
+		{name: "staff without food", file: "hungry.json", data: `{"startingCash": 0, "startingFiat": 0, "startingComputers": 0}`,
+			wantErr: "evicted in the first year"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			rules, err := LoadRules(path, DefaultRules)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rules.Name != tt.wantName || rules.StartingCash != tt.wantCash {
+				t.Errorf("got rules %q with %d cash, want %q with %d", rules.Name, rules.StartingCash, tt.wantName, tt.wantCash)
+			}
+		})
+	}
+}
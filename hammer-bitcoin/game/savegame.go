@@ -0,0 +1,87 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// saveVersion is the version of the save file format written by Save. It goes up whenever the
+// game state or the questions asked change, since an older save would resume with part of
+// the state missing or replay its answers to the wrong questions.
+const saveVersion = 8
+
+// DefaultSaveFile is where a game is saved when the player does not name a file
+const DefaultSaveFile = "bitcoin-miner-save.json"
+
+// SavedGame is a game interrupted part way through a year. Together with the seed and the
+// state of the random number generator, the answers already given this year put the
+// game back at the exact prompt it was saved from.
+type SavedGame struct {
+	Version     int       `json:"version"`
+	Seed        uint64    `json:"seed"`
+	RNG         []byte    `json:"rng"`
+	State       GameState `json:"state"`
+	Answers     []int     `json:"answers"`
+	YearAnswers []int     `json:"yearAnswers"`
+	// Ledger is the history of the years played before the save
+	Ledger Ledger `json:"ledger,omitempty"`
+}
+
+// versionError explains why a file of format version cannot be read by a game that reads want
+func versionError(version, want int) error {
+	if version > want {
+		return fmt.Errorf("the file is from a newer version of the game (format %d, this game reads %d), update the game to read it", version, want)
+	}
+	return fmt.Errorf("the file is from an older version of the game (format %d, this game reads %d) and cannot be played by this one, start a new game instead", version, want)
+}
+
+// LoadGame reads a save file written by Save
+func LoadGame(path string) (*SavedGame, error) {
+	var g SavedGame
+	if err := readJSONFile(path, &g); err != nil {
+		return nil, fmt.Errorf("loading game: %w", err)
+	}
+	if g.Version != saveVersion {
+		return nil, fmt.Errorf("loading game %s: %w", path, versionError(g.Version, saveVersion))
+	}
+	if len(g.RNG) == 0 {
+		return nil, fmt.Errorf("loading game %s: missing random number generator state", path)
+	}
+	if err := g.State.Rules.Validate(); err != nil {
+		return nil, fmt.Errorf("loading game %s: %w", path, err)
+	}
+	if g.State.Finished() {
+		return nil, fmt.Errorf("loading game %s: the game is already over", path)
+	}
+	return &g, nil
+}
+
+// Save writes the saved game to path as JSON
+func (g *SavedGame) Save(path string) error {
+	if err := writeJSONFile(path, g); err != nil {
+		return fmt.Errorf("saving game: %w", err)
+	}
+	return nil
+}
+
+// readJSONFile decodes the JSON file at path into v
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// writeJSONFile writes v to path as indented JSON
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
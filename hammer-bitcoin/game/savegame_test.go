@@ -0,0 +1,90 @@
+package game
+
+import (
+	"math/rand/v2"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSaveAndResume saves a game part way through a year, resumes it from the save file and
+// plays it to the end, and checks that it comes out the same as the game played in one go
+func TestSaveAndResume(t *testing.T) {
+	const seed, savedYear, answeredInYear = 7, 4, 3
+	want := playStrategy(t, Conservative{}, DefaultRules, seed)
+	if len(want.years) <= savedYear {
+		t.Fatalf("the game lasted %d years, too few to save in year %d", len(want.years), savedYear)
+	}
+
+	// play the years before the save the way Play does, on a source that can be saved
+	src := newSource(seed)
+	rng := rand.New(src)
+	s := NewGame(DefaultRules, rng)
+	var answers []int
+	for _, year := range want.years[:savedYear-1] {
+		d := Conservative{}.Decide(s.Summary())
+		s, _ = Step(s, d, rng)
+		answers = append(answers, year...)
+	}
+	rngState, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "save.json")
+	saved := SavedGame{
+		Version:     saveVersion,
+		Seed:        seed,
+		RNG:         rngState,
+		State:       s,
+		Answers:     answers,
+		YearAnswers: want.years[savedYear-1][:answeredInYear],
+		Ledger:      want.ledger[:savedYear-1],
+	}
+	if err := saved.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadGame(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the rest of the answers are typed after the game is resumed
+	SetInput(strings.NewReader(answerLines(want.answers[len(answers)+answeredInYear:])))
+	err = Play(Config{
+		Load:       loaded,
+		RecordFile: filepath.Join(dir, "recorded.json"),
+		LedgerFile: filepath.Join(dir, "ledger.json"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlayed(t, dir, want)
+}
+
+func TestLoadGameErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		game func(g *SavedGame)
+		want string
+	}{
+		{name: "newer version", game: func(g *SavedGame) { g.Version = saveVersion + 1 }, want: "update the game"},
+		{name: "older version", game: func(g *SavedGame) { g.Version = saveVersion - 1 }, want: "start a new game"},
+		{name: "no random number generator", game: func(g *SavedGame) { g.RNG = nil }, want: "missing random number generator state"},
+		{name: "game over", game: func(g *SavedGame) { g.State.Evicted = true }, want: "already over"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := SavedGame{Version: saveVersion, Seed: 1, RNG: []byte{1}, State: NewGame(DefaultRules, NewRand(1))}
+			tt.game(&g)
+			path := filepath.Join(t.TempDir(), "save.json")
+			if err := g.Save(path); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadGame(path); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
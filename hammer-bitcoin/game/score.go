@@ -0,0 +1,127 @@
+package game
+
+import "fmt"
+
+// Rating is the verdict on a finished term, as shown by printFinalScore
+type Rating int
+
+const (
+	Bankrupt Rating = iota
+	Terrible
+	Adequate
+	Good
+	Superb
+)
+
+// Ratings lists every rating from worst to best
+var Ratings = []Rating{Bankrupt, Terrible, Adequate, Good, Superb}
+
+// String returns the rating as printed at the end of the game
+func (r Rating) String() string {
+	switch r {
+	case Bankrupt:
+		return "BANKRUPT"
+	case Terrible:
+		return "TERRIBLE"
+	case Adequate:
+		return "ADEQUATE"
+	case Good:
+		return "GOOD"
+	case Superb:
+		return "SUPERB"
+	}
+	return "UNKNOWN"
+}
+
+// FinalRating rates a finished term. A corporation that could not pay the bank is bankrupt,
+// and too many starved employees in the last year means eviction; otherwise the rating
+// depends on how many computers the team can keep running, plus as many computers again as
+// the corporation's bitcoin and dollars could buy.
+func FinalRating(s GameState) Rating {
+	if s.Bankrupt {
+		return Bankrupt
+	}
+	if s.Evicted || s.Starved >= (s.Rules.EvictionThreshold*s.Employees)/100 {
+		return Terrible
+	}
+
+	computerScore := standing(s)
+
+	if computerScore < 600 {
+		return Adequate
+	} else if computerScore < 800 {
+		return Good
+	}
+	return Superb
+}
+
+// FinalScore is everything printFinalScore tells the player about a finished term
+type FinalScore struct {
+	Rating Rating `json:"rating"`
+	// Message is the verdict as printed at the end of the game, in the active locale
+	Message   string `json:"message"`
+	Starved   int    `json:"starved"`
+	Employees int    `json:"employees"`
+	Computers int    `json:"computers"`
+	Cash      int    `json:"cash"`
+	Fiat      int    `json:"fiat"`
+	// Debt is what the corporation still owes the bank
+	Debt int `json:"debt"`
+	// NetWorth is the cash and dollars together less the debt, in bitcoins
+	NetWorth int `json:"netWorth"`
+	Morale   int `json:"morale"`
+	Skill    int `json:"skill"`
+}
+
+// NewFinalScore returns the final score of a finished term
+func NewFinalScore(s GameState) FinalScore {
+	score := FinalScore{
+		Rating:    FinalRating(s),
+		Starved:   s.Starved,
+		Employees: s.Employees,
+		Computers: s.Computers,
+		Cash:      s.Cash,
+		Fiat:      s.Fiat,
+		Debt:      s.Debt,
+		NetWorth:  s.NetWorth(),
+		Morale:    s.Morale,
+		Skill:     s.Skill,
+	}
+
+	switch score.Rating {
+	case Bankrupt:
+		score.Message = Text("final.bankrupt", title(), s.Year-1)
+	case Terrible:
+		score.Message = Text("final.terrible", title(), s.Starved)
+	case Adequate:
+		score.Message = Text("final.adequate", title(), s.Rules.TermYears, s.Computers)
+	case Good:
+		score.Message = Text("final.good", title())
+	case Superb:
+		score.Message = Text("final.superb", title())
+	}
+	return score
+}
+
+// standing is the number of computers a term is rated on: the computers the team can keep
+// running, plus as many computers again as the corporation's bitcoin and dollars could buy
+func standing(s GameState) int {
+	computers := min(s.Computers, 20*s.Employees)
+	return computers + s.NetWorth()/s.ComputerPrice
+}
+
+// MarshalText writes the rating as printed at the end of the game
+func (r Rating) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText reads a rating written by MarshalText
+func (r *Rating) UnmarshalText(text []byte) error {
+	for _, rating := range Ratings {
+		if rating.String() == string(text) {
+			*r = rating
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown rating %q", text)
+}
@@ -0,0 +1,145 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Score is one finished game on the leaderboard
+type Score struct {
+	Name string    `json:"name"`
+	Date time.Time `json:"date"`
+	Seed uint64    `json:"seed"`
+	// Difficulty is the preset the game was played at, or "custom" for any other rules, so
+	// that they do not rank among the games played at a preset
+	Difficulty string `json:"difficulty"`
+	Rating     Rating `json:"rating"`
+	Computers  int    `json:"computers"`
+	Employees  int    `json:"employees"`
+	Cash       int    `json:"cash"`
+	Fiat       int    `json:"fiat"`
+}
+
+// NewScore returns the score for a finished game
+func NewScore(name string, seed uint64, s GameState) Score {
+	return Score{
+		Name:       name,
+		Date:       time.Now(),
+		Seed:       seed,
+		Difficulty: s.Rules.difficulty(),
+		Rating:     FinalRating(s),
+		Computers:  s.Computers,
+		Employees:  s.Employees,
+		Cash:       s.Cash,
+		Fiat:       s.Fiat,
+	}
+}
+
+// better reports whether a ranks above b: by rating, then computers, then cash
+func (a Score) better(b Score) bool {
+	if a.Rating != b.Rating {
+		return a.Rating > b.Rating
+	}
+	if a.Computers != b.Computers {
+		return a.Computers > b.Computers
+	}
+	return a.Cash > b.Cash
+}
+
+// Leaderboard is the list of finished games kept in a local JSON file
+type Leaderboard struct {
+	path   string
+	Scores []Score `json:"scores"`
+}
+
+// DefaultScoresFile returns where the leaderboard is kept unless told otherwise:
+// in the user's config directory, or the current directory if there is none
+func DefaultScoresFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "bitcoin-miner-scores.json"
+	}
+	return filepath.Join(dir, "hammer-bitcoin", "scores.json")
+}
+
+// LoadLeaderboard reads the leaderboard at path. A missing file is an empty leaderboard.
+func LoadLeaderboard(path string) (*Leaderboard, error) {
+	l := &Leaderboard{path: path}
+	err := readJSONFile(path, l)
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading scores: %w", err)
+	}
+	return l, nil
+}
+
+// Save writes the leaderboard back to the file it was loaded from
+func (l *Leaderboard) Save() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("saving scores: %w", err)
+	}
+	if err := writeJSONFile(l.path, l); err != nil {
+		return fmt.Errorf("saving scores: %w", err)
+	}
+	return nil
+}
+
+// Add records a score and returns its rank among the scores of the same difficulty,
+// and how many such scores there are
+func (l *Leaderboard) Add(score Score) (int, int) {
+	l.Scores = append(l.Scores, score)
+
+	rank, total := 1, 0
+	for _, other := range l.Scores {
+		if other.Difficulty != score.Difficulty {
+			continue
+		}
+		total++
+		if other.better(score) {
+			rank++
+		}
+	}
+	return rank, total
+}
+
+// Top returns the best n scores, best first. An empty difficulty includes every difficulty,
+// and n of 0 or less returns all of them.
+func (l *Leaderboard) Top(difficulty string, n int) []Score {
+	var scores []Score
+	for _, score := range l.Scores {
+		if difficulty == "" || score.Difficulty == difficulty {
+			scores = append(scores, score)
+		}
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].better(scores[j]) })
+	if n > 0 && len(scores) > n {
+		scores = scores[:n]
+	}
+	return scores
+}
+
+// PrintScores writes scores as a numbered table
+func PrintScores(w io.Writer, scores []Score) {
+	if len(scores) == 0 {
+		fmt.Fprintln(w, Text("scores.none"))
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, Text("scores.columns"))
+	for i, s := range scores {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\n",
+			i+1, s.Name, s.Date.Format("2006-01-02"), s.Difficulty, s.Rating, s.Computers, s.Employees, s.Cash, s.Fiat, s.Seed)
+	}
+	tw.Flush()
+}
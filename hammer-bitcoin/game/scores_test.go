@@ -0,0 +1,67 @@
+package game
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScoreDifficulty(t *testing.T) {
+	rich := filepath.Join(t.TempDir(), "rich.json")
+	if err := os.WriteFile(rich, []byte(`{"name": "normal", "startingCash": 28000}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	masquerading, err := LoadRules(rich, DefaultRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hard, err := Preset("hard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the rules of a saved game are read back from JSON
+	var saved Rules
+	data, err := json.Marshal(hard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	changed := hard.Clone()
+	changed.Events = changed.Events[1:]
+
+	tests := []struct {
+		name  string
+		rules Rules
+		want  string
+	}{
+		{name: "preset", rules: DefaultRules, want: "normal"},
+		{name: "another preset", rules: hard, want: "hard"},
+		{name: "preset of a saved game", rules: saved, want: "hard"},
+		{name: "preset with other events", rules: changed, want: "custom"},
+		{name: "file named after a preset", rules: masquerading, want: "custom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := NewScore("player", 1, NewGame(tt.rules, NewRand(1)))
+			if score.Difficulty != tt.want {
+				t.Errorf("got difficulty %q, want %q", score.Difficulty, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaderboardDifficulty(t *testing.T) {
+	l := &Leaderboard{}
+	l.Add(Score{Name: "fair", Difficulty: "normal", Rating: Good})
+	custom := NewGame(DefaultRules.Clone(), NewRand(1))
+	custom.Rules.StartingCash = 28000
+	if rank, total := l.Add(NewScore("rich", 1, custom)); rank != 1 || total != 1 {
+		t.Errorf("custom game ranked %d of %d, want 1 of 1", rank, total)
+	}
+	if top := l.Top("normal", 0); len(top) != 1 || top[0].Name != "fair" {
+		t.Errorf("got %+v on the normal leaderboard, want only the fair game", top)
+	}
+}
@@ -0,0 +1,331 @@
+package game
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultIdleTimeout is how long a hosted game is kept without a request before it is thrown away
+const DefaultIdleTimeout = 30 * time.Minute
+
+// maxRequestBody is the largest request body the server reads
+const maxRequestBody = 1 << 16
+
+// maxSessions is how many games the server hosts at once; more are turned away until some expire
+const maxSessions = 10000
+
+// requestTimeout is how long a client has to send a request, and the server to answer it
+const requestTimeout = 10 * time.Second
+
+// keepAlive is how long a connection is kept open for the next request
+const keepAlive = time.Minute
+
+// Server hosts games over HTTP, with a JSON body in and out of every request:
+//
+//	POST /games             starts a game, optionally with {"difficulty": "hard", "seed": 42}
+//	GET  /games/{id}        returns the game
+//	POST /games/{id}/turn   plays a year with a Decisions body
+//
+// Games are kept in memory and thrown away once they have been idle for longer than the idle
+// timeout. While maxSessions games are kept, no more can be started.
+type Server struct {
+	rules Rules
+	idle  time.Duration
+	mux   *http.ServeMux
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// session is one hosted game. Its lock is held for the whole of a request, so that two
+// turns played at once on the same game cannot both be applied.
+type session struct {
+	mu       sync.Mutex
+	id       string
+	seed     uint64
+	rng      *mathrand.Rand
+	state    GameState
+	lastYear *YearReport
+	lastUsed time.Time
+}
+
+// GameResponse is the JSON the server answers with. While the game is being played it
+// carries the summary printSummary shows; once it is over, the final score printFinalScore shows.
+type GameResponse struct {
+	ID   string `json:"id"`
+	Seed uint64 `json:"seed"`
+	// Summary is the corporation at the start of the year to decide on, unless the game is over
+	Summary *Summary `json:"summary,omitempty"`
+	// LastYear is what happened in the year just played, if any
+	LastYear   *YearReport `json:"lastYear,omitempty"`
+	Finished   bool        `json:"finished"`
+	FinalScore *FinalScore `json:"finalScore,omitempty"`
+}
+
+// NewGameRequest is the optional body of POST /games
+type NewGameRequest struct {
+	// Difficulty is the preset to play, or the server's rules if empty
+	Difficulty string `json:"difficulty"`
+	// Seed seeds the game. Zero picks a seed from the clock.
+	Seed uint64 `json:"seed"`
+}
+
+// ErrorResponse is the JSON the server answers with when a request fails
+type ErrorResponse struct {
+	Error string `json:"error"`
+	// Decision, Amount, Available and Reason explain a decision that was turned down
+	Decision  string `json:"decision,omitempty"`
+	Amount    int    `json:"amount,omitempty"`
+	Available int    `json:"available,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// NewServer returns a server that starts games played by rules, and throws games away once
+// they have been idle for longer than idle
+func NewServer(rules Rules, idle time.Duration) *Server {
+	if idle <= 0 {
+		idle = DefaultIdleTimeout
+	}
+	s := &Server{
+		rules:    rules,
+		idle:     idle,
+		mux:      http.NewServeMux(),
+		sessions: map[string]*session{},
+	}
+	s.mux.HandleFunc("POST /games", s.handleNewGame)
+	s.mux.HandleFunc("GET /games/{id}", s.handleGetGame)
+	s.mux.HandleFunc("POST /games/{id}/turn", s.handleTurn)
+	return s
+}
+
+// ServeHTTP serves the game API
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves the game API on addr, throwing idle games away as it goes
+func (s *Server) ListenAndServe(addr string) error {
+	go func() {
+		ticker := time.NewTicker(max(s.idle/4, time.Second))
+		defer ticker.Stop()
+		for now := range ticker.C {
+			s.ExpireIdle(now)
+		}
+	}()
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: requestTimeout,
+		ReadTimeout:       requestTimeout,
+		WriteTimeout:      requestTimeout,
+		IdleTimeout:       keepAlive,
+	}
+	return server.ListenAndServe()
+}
+
+// ExpireIdle throws away every game that has been idle since before now minus the idle
+// timeout, and returns how many it threw away
+func (s *Server) ExpireIdle(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := 0
+	for id, sess := range s.sessions {
+		// a session busy with a request is not idle
+		if !sess.mu.TryLock() {
+			continue
+		}
+		if now.Sub(sess.lastUsed) > s.idle {
+			delete(s.sessions, id)
+			expired++
+		}
+		sess.mu.Unlock()
+	}
+	return expired
+}
+
+// handleNewGame starts a game
+func (s *Server) handleNewGame(w http.ResponseWriter, r *http.Request) {
+	var req NewGameRequest
+	if err := decodeBody(w, r, &req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rules := s.rules
+	if req.Difficulty != "" {
+		var err error
+		if rules, err = Preset(req.Difficulty); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	seed := req.Seed
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	rng := NewRand(seed)
+	sess := &session{
+		id:       id,
+		seed:     seed,
+		rng:      rng,
+		state:    NewGame(rules, rng),
+		lastUsed: time.Now(),
+	}
+
+	s.mu.Lock()
+	full := len(s.sessions) >= maxSessions
+	if !full {
+		s.sessions[id] = sess
+	}
+	s.mu.Unlock()
+	if full {
+		writeError(w, http.StatusServiceUnavailable, errors.New("too many games are being played, try again later"))
+		return
+	}
+
+	w.Header().Set("Location", "/games/"+id)
+	writeJSON(w, http.StatusCreated, sess.response())
+}
+
+// handleGetGame returns a game
+func (s *Server) handleGetGame(w http.ResponseWriter, r *http.Request) {
+	sess := s.lock(r.PathValue("id"))
+	if sess == nil {
+		writeError(w, http.StatusNotFound, errors.New("no such game, or it expired"))
+		return
+	}
+	defer sess.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, sess.response())
+}
+
+// handleTurn plays a year of a game
+func (s *Server) handleTurn(w http.ResponseWriter, r *http.Request) {
+	var d Decisions
+	if err := decodeBody(w, r, &d); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sess := s.lock(r.PathValue("id"))
+	if sess == nil {
+		writeError(w, http.StatusNotFound, errors.New("no such game, or it expired"))
+		return
+	}
+	defer sess.mu.Unlock()
+
+	if sess.state.Finished() {
+		writeError(w, http.StatusConflict, errors.New("the game is over"))
+		return
+	}
+	if err := ValidateDecisions(sess.state, d); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var report YearReport
+	sess.state, report = Step(sess.state, d, sess.rng)
+	sess.lastYear = &report
+	writeJSON(w, http.StatusOK, sess.response())
+}
+
+// lock finds the game called id and locks it, or returns nil if there is no such game.
+// The caller unlocks the game once it is done with it.
+func (s *Server) lock(id string) *session {
+	s.mu.Lock()
+	sess, ok := s.sessions[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	sess.mu.Lock()
+	now := time.Now()
+	if now.Sub(sess.lastUsed) > s.idle {
+		// idle for too long, but not thrown away yet
+		sess.mu.Unlock()
+		return nil
+	}
+	sess.lastUsed = now
+	return sess
+}
+
+// response returns the game as the server answers with it
+func (sess *session) response() GameResponse {
+	resp := GameResponse{
+		ID:       sess.id,
+		Seed:     sess.seed,
+		LastYear: sess.lastYear,
+		Finished: sess.state.Finished(),
+	}
+	if resp.Finished {
+		score := NewFinalScore(sess.state)
+		resp.FinalScore = &score
+	} else {
+		summary := sess.state.Summary()
+		resp.Summary = &summary
+	}
+	return resp
+}
+
+// newSessionID returns a random, unguessable game id
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// decodeBody reads the JSON request body into v, turning down anything it does not know.
+// It returns io.EOF if the body is empty.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return err
+		}
+		return fmt.Errorf("reading request: %w", err)
+	}
+	return nil
+}
+
+// writeJSON answers with v as JSON
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// writeError answers with err as an ErrorResponse. A turned down decision is explained the
+// way the game explains it to the player.
+func writeError(w http.ResponseWriter, status int, err error) {
+	resp := ErrorResponse{Error: err.Error()}
+	var de *DecisionError
+	if errors.As(err, &de) {
+		resp.Error = jestMessage(de)
+		resp.Decision = de.Decision
+		resp.Amount = de.Amount
+		resp.Available = de.Available
+		resp.Reason = de.Err.Error()
+	}
+	writeJSON(w, status, resp)
+}
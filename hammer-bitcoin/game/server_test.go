@@ -0,0 +1,66 @@
+package game
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerNewGame(t *testing.T) {
+	s := NewServer(DefaultRules, time.Minute)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/games", "application/json", strings.NewReader(`{"difficulty": "hard", "seed": 42}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	var game GameResponse
+	if err := json.NewDecoder(resp.Body).Decode(&game); err != nil {
+		t.Fatal(err)
+	}
+	if game.Seed != 42 || game.Summary == nil || game.Summary.Year != 1 {
+		t.Errorf("got %+v, want the first year of seed 42", game)
+	}
+}
+
+func TestServerFull(t *testing.T) {
+	s := NewServer(DefaultRules, time.Minute)
+	for i := range maxSessions {
+		s.sessions[strconv.Itoa(i)] = &session{lastUsed: time.Now()}
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/games", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if len(s.sessions) != maxSessions {
+		t.Errorf("the server holds %d games, want %d", len(s.sessions), maxSessions)
+	}
+
+	// once a game expires there is room for another
+	s.sessions["0"].lastUsed = time.Now().Add(-time.Hour)
+	s.ExpireIdle(time.Now())
+	resp, err = http.Post(ts.URL+"/games", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+}
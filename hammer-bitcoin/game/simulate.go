@@ -0,0 +1,231 @@
+package game
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Sweep is a rule and the values the analyzer should try it with
+type Sweep struct {
+	Param  string
+	Values []int
+}
+
+// ParseSweep reads a sweep written as param=from:to:step or param=v1,v2,v3,
+// for example crash=5:25:5 or yieldmax=4,6,8
+func ParseSweep(s string) (Sweep, error) {
+	param, values, ok := strings.Cut(s, "=")
+	if !ok {
+		return Sweep{}, fmt.Errorf("sweep %q: expected param=values", s)
+	}
+	sweep := Sweep{Param: param}
+
+	if bounds := strings.Split(values, ":"); len(bounds) == 3 {
+		var n [3]int
+		for i, b := range bounds {
+			v, err := strconv.Atoi(b)
+			if err != nil {
+				return Sweep{}, fmt.Errorf("sweep %q: %w", s, err)
+			}
+			n[i] = v
+		}
+		from, to, step := n[0], n[1], n[2]
+		if step <= 0 || from > to {
+			return Sweep{}, fmt.Errorf("sweep %q: expected from <= to and a positive step", s)
+		}
+		for v := from; v <= to; v += step {
+			sweep.Values = append(sweep.Values, v)
+		}
+		return sweep, nil
+	}
+
+	for _, field := range strings.Split(values, ",") {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return Sweep{}, fmt.Errorf("sweep %q: %w", s, err)
+		}
+		sweep.Values = append(sweep.Values, v)
+	}
+	return sweep, nil
+}
+
+// SimulationResult sums up the games played under one set of rules
+type SimulationResult struct {
+	Rules    Rules
+	Games    int
+	Forfeits int
+	Evicted  int
+	Ratings  map[Rating]int
+	// EvictionYears counts evictions by the year they happened in; index 0 is unused
+	EvictionYears []int
+}
+
+// EvictionRate returns the share of games in which the player was evicted
+func (r SimulationResult) EvictionRate() float64 {
+	if r.Games == 0 {
+		return 0
+	}
+	return float64(r.Evicted) / float64(r.Games)
+}
+
+// Simulate plays games seeded games with strategy st for every combination of the swept
+// values, starting from the base rules. Combinations that make invalid rules are not played,
+// and are returned as skipped, each with why its rules are invalid. It is an error when no
+// combination is valid.
+func Simulate(st Strategy, base Rules, sweeps []Sweep, games int, seed uint64) ([]SimulationResult, []error, error) {
+	for _, sweep := range sweeps {
+		check := base.Clone()
+		if err := check.Set(sweep.Param, 0); err != nil {
+			return nil, nil, fmt.Errorf("sweep %s: %w", sweep.Param, err)
+		}
+	}
+
+	var results []SimulationResult
+	var skipped []error
+
+	for _, rules := range combineSweeps(base, sweeps) {
+		if err := rules.Validate(); err != nil {
+			skipped = append(skipped, fmt.Errorf("%s: %w", sweptValues(rules, sweeps), err))
+			continue
+		}
+
+		result := SimulationResult{
+			Rules:         rules,
+			Games:         games,
+			Ratings:       map[Rating]int{},
+			EvictionYears: make([]int, rules.TermYears+1),
+		}
+		for i := 0; i < games; i++ {
+			final, err := RunGame(st, rules, seed+uint64(i))
+			if err != nil {
+				result.Forfeits++
+				continue
+			}
+
+			result.Ratings[FinalRating(final)]++
+			if final.Evicted {
+				result.Evicted++
+				// the year has already moved on when the term ends
+				result.EvictionYears[final.Year-1]++
+			}
+		}
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		return nil, skipped, fmt.Errorf("none of the %d combinations of swept values makes valid rules", len(skipped))
+	}
+	return results, skipped, nil
+}
+
+// sweptValues describes the values rules take for the swept params, such as "crash=5 yieldmax=4"
+func sweptValues(rules Rules, sweeps []Sweep) string {
+	var values []string
+	for _, sweep := range sweeps {
+		values = append(values, fmt.Sprintf("%s=%d", sweep.Param, rules.Get(sweep.Param)))
+	}
+	return strings.Join(values, " ")
+}
+
+// combineSweeps returns the rules for every combination of the swept values
+func combineSweeps(base Rules, sweeps []Sweep) []Rules {
+	combinations := []Rules{base}
+	for _, sweep := range sweeps {
+		var next []Rules
+		for _, rules := range combinations {
+			for _, v := range sweep.Values {
+				combination := rules.Clone()
+				_ = combination.Set(sweep.Param, v)
+				next = append(next, combination)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// longestTerm returns the longest term among the results, which decides how many
+// eviction year columns are reported
+func longestTerm(results []SimulationResult) int {
+	term := 0
+	for _, result := range results {
+		term = max(term, result.Rules.TermYears)
+	}
+	return term
+}
+
+// simulationParams returns the rules reported for each result: the plain rules, then the
+// settings of every event declared in the first result's rules
+func simulationParams(results []SimulationResult) []string {
+	params := append([]string{}, RuleParams...)
+	if len(results) > 0 {
+		params = append(params, results[0].Rules.EventParams()...)
+	}
+	return params
+}
+
+// simulationHeader returns the column names shared by the CSV and the table
+func simulationHeader(params []string, term int) []string {
+	header := append([]string{}, params...)
+	header = append(header, "games", "forfeits", "eviction rate")
+	for _, r := range Ratings {
+		header = append(header, r.String())
+	}
+	for year := 1; year <= term; year++ {
+		header = append(header, fmt.Sprintf("evicted y%d", year))
+	}
+	return header
+}
+
+// simulationRow returns one result as a row of columns matching simulationHeader
+func simulationRow(result SimulationResult, params []string, term int) []string {
+	var row []string
+	for _, param := range params {
+		row = append(row, strconv.Itoa(result.Rules.Get(param)))
+	}
+	row = append(row,
+		strconv.Itoa(result.Games),
+		strconv.Itoa(result.Forfeits),
+		fmt.Sprintf("%.3f", result.EvictionRate()))
+	for _, r := range Ratings {
+		row = append(row, strconv.Itoa(result.Ratings[r]))
+	}
+	for year := 1; year <= term; year++ {
+		evictions := 0
+		if year < len(result.EvictionYears) {
+			evictions = result.EvictionYears[year]
+		}
+		row = append(row, strconv.Itoa(evictions))
+	}
+	return row
+}
+
+// WriteSimulationCSV writes one CSV row per set of rules
+func WriteSimulationCSV(w io.Writer, results []SimulationResult) error {
+	params, term := simulationParams(results), longestTerm(results)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(simulationHeader(params, term)); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if err := cw.Write(simulationRow(result, params, term)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// PrintSimulation writes the results as a table, one row per set of rules
+func PrintSimulation(w io.Writer, results []SimulationResult) {
+	params, term := simulationParams(results), longestTerm(results)
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, strings.Join(simulationHeader(params, term), "\t")+"\t")
+	for _, result := range results {
+		fmt.Fprintln(tw, strings.Join(simulationRow(result, params, term), "\t")+"\t")
+	}
+	tw.Flush()
+}
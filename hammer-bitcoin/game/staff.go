@@ -0,0 +1,97 @@
+package game
+
+// Morale runs from 0 to 100. At neutralMorale the employees hire, work and resist the
+// hackers just as in the original game.
+const (
+	neutralMorale = 50
+	maxMorale     = 100
+	// maxMoraleGain and maxMoraleLoss bound how far morale can move in one year on pay alone
+	maxMoraleGain = 15
+	maxMoraleLoss = 30
+)
+
+// Skill runs from 0, a team of new hires, to maxSkill. Every year on the job adds to it.
+const (
+	maxSkill = 100
+	// experienceHappy and experienceUnhappy are the skill a year on the job adds with morale
+	// at or above neutralMorale, and below it
+	experienceHappy   = 10
+	experienceUnhappy = 4
+)
+
+// mood returns how employees with the given morale feel about the corporation, in the active locale
+func mood(morale int) string {
+	switch {
+	case morale < 20:
+		return Text("mood.mutinous")
+	case morale < 40:
+		return Text("mood.unhappy")
+	case morale < 60:
+		return Text("mood.content")
+	case morale < 80:
+		return Text("mood.happy")
+	}
+	return Text("mood.devoted")
+}
+
+// moraleChange returns how much morale moves when the employees are paid cashPaid between
+// them and percentStarved of them starve. Pay below the living cost hurts twice as much as
+// the same amount above it helps, and pay of exactly the living cost lets morale drift back
+// towards neutral.
+func moraleChange(morale, employees, cashPaid, livingCost, percentStarved int) int {
+	if employees <= 0 {
+		return 0
+	}
+	// the pay of each employee compared to the living cost, in percent
+	paid := (100 * cashPaid) / (employees * livingCost)
+
+	var change int
+	switch {
+	case paid < 100:
+		change = -min((100-paid)/2, maxMoraleLoss)
+	case paid > 100:
+		change = min((paid-100)/4, maxMoraleGain)
+	case morale > neutralMorale:
+		change = -min(5, morale-neutralMorale)
+	case morale < neutralMorale:
+		change = min(5, neutralMorale-morale)
+	}
+	return change - percentStarved/2
+}
+
+// updateMorale moves the morale of the employees after they were paid cashPaid between them
+func (s *GameState) updateMorale(employees, cashPaid, percentStarved int) {
+	s.Morale = s.Morale + moraleChange(s.Morale, employees, cashPaid, s.Rules.LivingCost, percentStarved)
+	s.Morale = min(max(s.Morale, 0), maxMorale)
+}
+
+// gainExperience adds a year on the job to the veterans' skill, then brings in the new hires,
+// who start with none and bring the average down
+func (s *GameState) gainExperience(veterans, hires int) {
+	skill := s.Skill + experienceUnhappy
+	if s.Morale >= neutralMorale {
+		skill = s.Skill + experienceHappy
+	}
+	skill = min(skill, maxSkill)
+
+	if veterans+hires > 0 {
+		s.Skill = (skill * veterans) / (veterans + hires)
+	}
+}
+
+// skillBonus is how much further the maintenance budget goes thanks to the staff's skill, in percent
+func skillBonus(skill int) int {
+	return skill / 4
+}
+
+// computersMaintained returns how many computers maintenanceAmount keeps running. Every
+// computer takes the power of its generation, or a little less with a skilled staff.
+func computersMaintained(s GameState, maintenanceAmount int) int {
+	return s.maintainable(maintenanceAmount * (100 + skillBonus(s.Skill)) / 100)
+}
+
+// moraleHires scales the new hires by the morale of the employees: a happy corporation
+// is recommended by its staff, an unhappy one is not
+func moraleHires(hires, morale int) int {
+	return hires * (neutralMorale + morale) / (2 * neutralMorale)
+}
@@ -0,0 +1,141 @@
+package game
+
+import "fmt"
+
+// Strategy plays the game without a player: each year it looks at the same summary
+// the player would see and decides what to do
+type Strategy interface {
+	// Name identifies the strategy in tournament results
+	Name() string
+	// Decide returns the decisions for the year described by s
+	Decide(s Summary) Decisions
+}
+
+// Strategies returns the reference strategies that ship with the game
+func Strategies() []Strategy {
+	return []Strategy{Greedy{}, Conservative{}, FeedEveryoneFirst{}}
+}
+
+// RunGame plays a whole term by rules with strategy st, using seed for the random events.
+// It returns the final state, or an error if the strategy made a decision the rules don't allow.
+func RunGame(st Strategy, rules Rules, seed uint64) (GameState, error) {
+	rng := NewRand(seed)
+	state := NewGame(rules, rng)
+
+	for !state.Finished() {
+		d := st.Decide(state.Summary())
+		if err := ValidateDecisions(state, d); err != nil {
+			return state, fmt.Errorf("%s in year %d: %w", st.Name(), state.Year, err)
+		}
+		state, _ = Step(state, d, rng)
+	}
+	return state, nil
+}
+
+// StrategyByName returns the built-in strategy called name
+func StrategyByName(name string) (Strategy, error) {
+	for _, st := range Strategies() {
+		if st.Name() == name {
+			return st, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown strategy %q", name)
+}
+
+// Greedy puts most of the cash into computers, pays the employees with what is left,
+// and maintains as many computers as it can
+type Greedy struct{}
+
+// Name returns the strategy's name
+func (Greedy) Name() string { return "greedy" }
+
+// Decide spends 80% of the cash on computers of the best generation on sale
+func (Greedy) Decide(s Summary) Decisions {
+	var d Decisions
+	offer := bestOffer(s.Catalog)
+	d.Generation = offer.Generation
+	d.ComputersToBuy = (s.Cash * 8 / 10) / offer.Price
+	cash := s.Cash - d.ComputersToBuy*offer.Price
+
+	d.CashPaidToEmployees = min(cash, s.Rules.LivingCost*s.Employees)
+	cash = cash - d.CashPaidToEmployees
+
+	fleet := s.fleetAfterBuying(offer, d.ComputersToBuy)
+	d.MaintenanceAmount = min(cash, fleet.powerCost(s.Rules.ComputersPerEmployee*s.Employees))
+	return d
+}
+
+// Conservative never buys or sells computers. It pays the employees their living costs
+// and maintains as many computers as it can.
+type Conservative struct{}
+
+// Name returns the strategy's name
+func (Conservative) Name() string { return "conservative" }
+
+// Decide pays the employees and keeps the existing computers running
+func (Conservative) Decide(s Summary) Decisions {
+	var d Decisions
+	d.CashPaidToEmployees = min(s.Cash, s.Rules.LivingCost*s.Employees)
+	cash := s.Cash - d.CashPaidToEmployees
+
+	fleet := s.fleetAfterBuying(Offer{}, 0)
+	d.MaintenanceAmount = min(cash, fleet.powerCost(s.Rules.ComputersPerEmployee*s.Employees))
+	return d
+}
+
+// FeedEveryoneFirst pays the employees before anything else, then buys as many
+// computers as the staff can run while keeping enough cash to maintain them all
+type FeedEveryoneFirst struct{}
+
+// Name returns the strategy's name
+func (FeedEveryoneFirst) Name() string { return "feed-everyone-first" }
+
+// Decide sets aside the living costs, then invests what is left in computers
+func (FeedEveryoneFirst) Decide(s Summary) Decisions {
+	var d Decisions
+	offer := bestOffer(s.Catalog)
+	d.Generation = offer.Generation
+	pay := min(s.Cash, s.Rules.LivingCost*s.Employees)
+	cash := s.Cash - pay
+
+	// every computer bought costs its price plus its power in maintenance budget,
+	// and there is no point buying more than the staff can look after
+	staffed := s.Rules.ComputersPerEmployee * s.Employees
+	if s.Computers < staffed {
+		running := s.fleetAfterBuying(offer, 0).powerCost(staffed)
+		affordable := max(0, (cash-running)/(offer.Price+offer.Power))
+		d.ComputersToBuy = min(affordable, staffed-s.Computers)
+	}
+	cash = cash - d.ComputersToBuy*offer.Price
+
+	d.CashPaidToEmployees = pay
+	fleet := s.fleetAfterBuying(offer, d.ComputersToBuy)
+	d.MaintenanceAmount = min(cash, fleet.powerCost(staffed))
+	return d
+}
+
+// bestOffer returns the offer in the catalog that mines the most for what a computer costs
+// to buy and run for a year, and of those the first
+func bestOffer(catalog []Offer) Offer {
+	best := catalog[0]
+	for _, o := range catalog[1:] {
+		if o.Yield*(best.Price+best.Power) > best.Yield*(o.Price+o.Power) {
+			best = o
+		}
+	}
+	return best
+}
+
+// fleetAfterBuying returns a state holding the fleet of the summary and bought computers of
+// offer, to work out the power it takes to run them. The summary does not tell the ages of the
+// computers, but those only decide which computers of the same generation run first.
+func (s Summary) fleetAfterBuying(offer Offer, bought int) GameState {
+	state := GameState{Rules: s.Rules}
+	for _, h := range s.Fleet {
+		state.Fleet = append(state.Fleet, Batch{Generation: h.Generation, Count: h.Count})
+	}
+	if bought > 0 {
+		state.Fleet = append(state.Fleet, Batch{Generation: offer.Generation, Count: bought})
+	}
+	return state
+}
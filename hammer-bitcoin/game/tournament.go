@@ -0,0 +1,83 @@
+package game
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// TournamentResult sums up how one strategy did over a tournament
+type TournamentResult struct {
+	Strategy      string
+	Games         int
+	Wins          int
+	Forfeits      int
+	Ratings       map[Rating]int
+	MeanComputers float64
+}
+
+// WinRate returns the share of games that ended without the player being evicted or going bankrupt
+func (r TournamentResult) WinRate() float64 {
+	if r.Games == 0 {
+		return 0
+	}
+	return float64(r.Wins) / float64(r.Games)
+}
+
+// RunTournament plays games seeded games by rules with every strategy. Game i uses seed+i for
+// every strategy, so they all face the same random events. A game counts as won when it
+// ends with any rating better than TERRIBLE. Games where a strategy breaks the rules are
+// counted as forfeits and left out of the ratings.
+func RunTournament(strategies []Strategy, rules Rules, games int, seed uint64) []TournamentResult {
+	results := make([]TournamentResult, 0, len(strategies))
+
+	for _, st := range strategies {
+		result := TournamentResult{
+			Strategy: st.Name(),
+			Games:    games,
+			Ratings:  map[Rating]int{},
+		}
+
+		totalComputers := 0
+		for i := 0; i < games; i++ {
+			final, err := RunGame(st, rules, seed+uint64(i))
+			if err != nil {
+				result.Forfeits++
+				continue
+			}
+
+			rating := FinalRating(final)
+			result.Ratings[rating]++
+			if rating > Terrible {
+				result.Wins++
+			}
+			totalComputers += final.Computers
+		}
+
+		if played := games - result.Forfeits; played > 0 {
+			result.MeanComputers = float64(totalComputers) / float64(played)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// PrintTournament writes the tournament results as a table
+func PrintTournament(w io.Writer, results []TournamentResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprint(tw, "strategy\tgames\twin rate\t")
+	for _, r := range Ratings {
+		fmt.Fprintf(tw, "%s\t", r)
+	}
+	fmt.Fprint(tw, "forfeits\tmean computers\t\n")
+
+	for _, result := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\t", result.Strategy, result.Games, 100*result.WinRate())
+		for _, r := range Ratings {
+			fmt.Fprintf(tw, "%d\t", result.Ratings[r])
+		}
+		fmt.Fprintf(tw, "%d\t%.1f\t\n", result.Forfeits, result.MeanComputers)
+	}
+	tw.Flush()
+}
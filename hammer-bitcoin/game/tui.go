@@ -0,0 +1,394 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"github.com/eiannone/keyboard"
+	"github.com/fatih/color"
+)
+
+// ANSI escape sequences used to draw the dashboard. color.Output understands them on
+// every platform, so the game never has to shell out to clear the screen.
+const (
+	ansiClear      = "\033[H\033[2J"
+	ansiHideCursor = "\033[?25l"
+	ansiShowCursor = "\033[?25h"
+)
+
+// errNotANumber turns down a form field that does not hold a whole number
+var errNotANumber = errors.New("not a whole number")
+
+// errQuit is returned by the dashboard when the player quits before the term is over
+var errQuit = errors.New("the player quit")
+
+// sparkBars are the bars a sparkline is drawn with, from lowest to highest
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as a row of bars scaled between their lowest and highest value
+func sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		bar := 0
+		if hi > lo {
+			bar = (v - lo) * (len(sparkBars) - 1) / (hi - lo)
+		}
+		b.WriteRune(sparkBars[bar])
+	}
+	return b.String()
+}
+
+// gauge is one of the numbers on the dashboard, along with its value at the start of every year so far
+type gauge struct {
+	// label is the message ID of the gauge's label
+	label   string
+	value   func(GameState) int
+	history []int
+}
+
+// formField is one of the decisions typed into the dashboard's form
+type formField struct {
+	label func(GameState) string
+	input string
+	// initial is what the field holds at the start of every year, if not 0
+	initial  string
+	validate func(GameState, int) error
+	// apply carries the decision out, so that the fields after it are checked against what is left
+	apply func(*GameState, int)
+}
+
+// dashboard is the full-screen way to play: the corporation stays on screen with a sparkline
+// of its history, and the year's decisions are typed into a form that is checked as it is typed
+type dashboard struct {
+	gauges []*gauge
+	fields []*formField
+	focus  int
+	status string
+	state  GameState
+}
+
+// newDashboard returns a dashboard with an empty form and no history
+func newDashboard() *dashboard {
+	return &dashboard{
+		gauges: []*gauge{
+			{label: "tui.gauge.cash", value: func(s GameState) int { return s.Cash }},
+			{label: "tui.gauge.fiat", value: func(s GameState) int { return s.Fiat }},
+			{label: "tui.gauge.rate", value: func(s GameState) int { return s.ExchangeRate }},
+			{label: "tui.gauge.computers", value: func(s GameState) int { return s.Computers }},
+			{label: "tui.gauge.employees", value: func(s GameState) int { return s.Employees }},
+			{label: "tui.gauge.morale", value: func(s GameState) int { return s.Morale }},
+			{label: "tui.gauge.skill", value: func(s GameState) int { return s.Skill }},
+			{label: "tui.gauge.price", value: func(s GameState) int { return s.ComputerPrice }},
+			{label: "tui.gauge.debt", value: func(s GameState) int { return s.Debt }},
+		},
+		fields: []*formField{
+			{
+				label: func(s GameState) string {
+					return Text("tui.field.borrow", s.Credit(), s.Rules.InterestRate)
+				},
+				validate: validateLoan,
+				apply:    (*GameState).borrow,
+			},
+			{
+				label: func(s GameState) string {
+					return Text("tui.field.convert", s.ExchangeRate)
+				},
+				validate: validateConvert,
+				apply:    (*GameState).convert,
+			},
+			{
+				label: func(s GameState) string {
+					return Text("tui.field.generation", len(s.Catalog()))
+				},
+				initial:  "1",
+				validate: validateOffer,
+				apply: func(s *GameState, n int) {
+					o, _ := s.offer(n)
+					s.choose(o.Generation)
+				},
+			},
+			{
+				label: func(s GameState) string {
+					return Text("tui.field.buy")
+				},
+				validate: validateBuy,
+				apply:    (*GameState).buy,
+			},
+			{
+				label: func(s GameState) string {
+					return Text("tui.field.sell")
+				},
+				validate: validateSell,
+				apply:    (*GameState).sell,
+			},
+			{
+				label: func(s GameState) string {
+					return Text("tui.field.pay", s.Rules.LivingCost)
+				},
+				validate: validatePay,
+				apply:    (*GameState).pay,
+			},
+			{
+				label: func(s GameState) string {
+					return Text("tui.field.maintain")
+				},
+				validate: validateMaintenance,
+			},
+		},
+	}
+}
+
+// startYear shows the state at the start of a year, adds it to the history and clears the form
+func (d *dashboard) startYear(s GameState) {
+	d.state = s
+	for _, g := range d.gauges {
+		g.history = append(g.history, g.value(s))
+	}
+	for _, f := range d.fields {
+		f.input = "0"
+		if f.initial != "" {
+			f.input = f.initial
+		}
+	}
+	d.focus = 0
+}
+
+// check checks every field in order, each against what the corporation has left after the
+// fields before it. It returns the answers, an error for every field that is turned down,
+// and what the corporation has left once the valid answers are carried out.
+func (d *dashboard) check() ([]int, []error, GameState) {
+	s := d.state
+	answers := make([]int, len(d.fields))
+	errs := make([]error, len(d.fields))
+	for i, f := range d.fields {
+		n, err := strconv.Atoi(f.input)
+		if err != nil {
+			errs[i] = errNotANumber
+			continue
+		}
+		if err := f.validate(s, n); err != nil {
+			errs[i] = err
+			continue
+		}
+		answers[i] = n
+		if f.apply != nil {
+			f.apply(&s, n)
+		}
+	}
+	return answers, errs, s
+}
+
+// decisions returns the decisions in the form, or false if any field is turned down
+func (d *dashboard) decisions() ([]int, Decisions, bool) {
+	answers, errs, _ := d.check()
+	for _, err := range errs {
+		if err != nil {
+			return nil, Decisions{}, false
+		}
+	}
+	offer, _ := d.state.offer(answers[2])
+	return answers, Decisions{
+		Borrow:              answers[0],
+		BitcoinToSell:       answers[1],
+		Generation:          offer.Generation,
+		ComputersToBuy:      answers[3],
+		ComputersToSell:     answers[4],
+		CashPaidToEmployees: answers[5],
+		MaintenanceAmount:   answers[6],
+	}, true
+}
+
+// render draws the whole dashboard
+func (d *dashboard) render() string {
+	var b strings.Builder
+	s := d.state
+	rule := strings.Repeat("─", 72) + "\n"
+	bold := color.New(color.Bold).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	magenta := color.New(color.FgMagenta).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	b.WriteString(ansiClear)
+	fmt.Fprintf(&b, "%s  %s\n", bold(Text("tui.title")), Text("tui.year", s.Year, s.Rules.TermYears, s.Rules.Name))
+	b.WriteString(rule)
+	for _, g := range d.gauges {
+		fmt.Fprintf(&b, " %-20s %8d   %s\n", Text(g.label), g.value(s), cyan(sparkline(g.history)))
+	}
+	b.WriteString(rule)
+
+	if s.Year > 1 {
+		fmt.Fprintf(&b, " %s\n", Text("tui.lastYear", s.Starved, s.NewEmployees, s.CashMined, s.BitcoinGeneratedPerComputer))
+	} else {
+		fmt.Fprintf(&b, " %s\n", Text("tui.firstYear", title()))
+	}
+	for _, event := range s.Events {
+		b.WriteString(red(fmt.Sprintf(" *** %s ***", event.Message)) + "\n")
+	}
+	b.WriteString(rule)
+	for _, o := range s.Catalog() {
+		fmt.Fprintf(&b, " %s\n", Text("catalog.offer", o.Number, generationName(o.Generation), o.Price, o.Yield, o.Power, o.FailureRate))
+	}
+	b.WriteString(rule)
+
+	_, errs, left := d.check()
+	for i, f := range d.fields {
+		cursor := "  "
+		input := fmt.Sprintf(" %8s ", f.input)
+		if i == d.focus {
+			cursor = "> "
+			input = bold(fmt.Sprintf("[%8s]", f.input))
+		}
+		fmt.Fprintf(&b, "%s%-52s %s\n", cursor, f.label(s), input)
+		if errs[i] != nil {
+			b.WriteString(magenta(fmt.Sprintf("    %s %s", Text("jest.dreaming", title()), jestMessage(errs[i]))) + "\n")
+		}
+	}
+	fmt.Fprintf(&b, "\n %s\n", Text("tui.after", left.Cash, left.Fiat, left.Computers))
+	b.WriteString(rule)
+	fmt.Fprintf(&b, " %s\n", Text("tui.keys"))
+	if d.status != "" {
+		fmt.Fprintf(&b, " %s\n", d.status)
+	}
+	return b.String()
+}
+
+// edit applies a key pressed while the focused field is being typed in
+func (d *dashboard) edit(char rune, key keyboard.Key) {
+	f := d.fields[d.focus]
+	switch {
+	case key == keyboard.KeyBackspace || key == keyboard.KeyBackspace2:
+		if len(f.input) > 0 {
+			f.input = f.input[:len(f.input)-1]
+		}
+	case char >= '0' && char <= '9':
+		if f.input == "0" {
+			f.input = ""
+		}
+		f.input = f.input + string(char)
+	case char == '-':
+		if strings.HasPrefix(f.input, "-") {
+			f.input = f.input[1:]
+		} else {
+			f.input = "-" + f.input
+		}
+	}
+}
+
+// playDashboard plays the rest of the term on the full-screen dashboard. The decisions are
+// recorded on the console just as if they had been typed at its prompts, so replays and saves
+// work the same in both modes. It returns false if the player quit before the term was over,
+// or with the error if the keyboard could no longer be read.
+func playDashboard(in *console, state *GameState, rng *rand.Rand) (bool, error) {
+	if err := keyboard.Open(); err != nil {
+		log.Printf("cannot use the dashboard, playing on the console instead: %v", err)
+		return true, playConsole(in, state, rng)
+	}
+	defer func() {
+		_ = keyboard.Close()
+		fmt.Fprint(color.Output, ansiShowCursor)
+	}()
+
+	fmt.Println(Text("tui.open"))
+	if _, _, err := keyboard.GetKey(); err != nil {
+		return false, fmt.Errorf("reading the keyboard: %w", err)
+	}
+	fmt.Fprint(color.Output, ansiHideCursor)
+
+	d := newDashboard()
+	for !state.Finished() {
+		in.startYear()
+		d.startYear(*state)
+
+		// a saved game may have been saved partway through the year
+		for i, answer := range in.playback {
+			if i < len(d.fields) {
+				d.fields[i].input = strconv.Itoa(answer)
+			}
+		}
+		in.playback = nil
+
+		answers, decisions, err := d.fill(in)
+		if errors.Is(err, errQuit) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		for _, answer := range answers {
+			in.record(answer)
+		}
+
+		before := *state
+		var report YearReport
+		*state, report = Step(*state, decisions, rng)
+		in.endYear(before, decisions, *state, report)
+		d.status = ""
+		if !state.Finished() {
+			// the achievements of the last year are shown with the final score
+			var unlocked []string
+			for _, a := range in.takeEarned() {
+				unlocked = append(unlocked, Text("achievement.unlocked", a.Name(), a.Description()))
+			}
+			d.status = strings.Join(unlocked, "\n ")
+		}
+	}
+	return true, nil
+}
+
+// fill lets the player fill in the form until every field is valid and the year is ended,
+// and returns the answers in the order the console asks for them. It returns errQuit if the
+// player quits, and the error if the keyboard can no longer be read, such as when the
+// terminal is gone.
+func (d *dashboard) fill(in *console) ([]int, Decisions, error) {
+	for {
+		fmt.Fprint(color.Output, d.render())
+
+		char, key, err := keyboard.GetKey()
+		if err != nil {
+			fmt.Fprint(color.Output, ansiClear)
+			return nil, Decisions{}, fmt.Errorf("reading the keyboard: %w", err)
+		}
+
+		switch key {
+		case keyboard.KeyEsc, keyboard.KeyCtrlC:
+			fmt.Fprint(color.Output, ansiClear)
+			return nil, Decisions{}, errQuit
+		case keyboard.KeyArrowUp:
+			d.focus = (d.focus + len(d.fields) - 1) % len(d.fields)
+		case keyboard.KeyArrowDown, keyboard.KeyTab:
+			d.focus = (d.focus + 1) % len(d.fields)
+		case keyboard.KeyCtrlS:
+			// the form is only saved as the start of the year, since nothing in it has been decided yet
+			if err := in.save(DefaultSaveFile); err != nil {
+				d.status = err.Error()
+			} else {
+				d.status = Text("console.saved", DefaultSaveFile)
+			}
+		case keyboard.KeyEnter:
+			if d.focus < len(d.fields)-1 {
+				d.focus++
+				continue
+			}
+			answers, decisions, ok := d.decisions()
+			if ok {
+				return answers, decisions, nil
+			}
+			d.status = Text("tui.cannotEnd")
+		default:
+			d.edit(char, key)
+		}
+	}
+}
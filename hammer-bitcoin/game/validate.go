@@ -0,0 +1,206 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The reasons a decision can be turned down. Use errors.Is to tell them apart.
+var (
+	ErrNegativeAmount     = errors.New("amount cannot be negative")
+	ErrInsufficientCash   = errors.New("not enough cash")
+	ErrInsufficientFiat   = errors.New("not enough dollars")
+	ErrNotEnoughComputers = errors.New("not enough computers")
+	ErrNotEnoughStaff     = errors.New("not enough staff")
+	ErrNotOnSale          = errors.New("not on sale")
+	ErrOverCreditLimit    = errors.New("over the credit limit")
+	ErrNotOwed            = errors.New("more than is owed")
+)
+
+// The decisions a DecisionError can be about
+const (
+	DecisionLoan       = "loan"
+	DecisionConvert    = "convert"
+	DecisionBuy        = "buy"
+	DecisionSell       = "sell"
+	DecisionPay        = "pay"
+	DecisionMaintain   = "maintain"
+	DecisionGeneration = "generation"
+)
+
+// DecisionError explains why a decision was turned down
+type DecisionError struct {
+	// Decision is one of DecisionLoan, DecisionConvert, DecisionGeneration, DecisionBuy, DecisionSell,
+	// DecisionPay or DecisionMaintain
+	Decision string
+	// Amount is what the decision needed: the bitcoins or dollars it costs, or the computers it sells
+	Amount int
+	// Available is what the corporation had to cover it
+	Available int
+	// Err is one of the Err values above
+	Err error
+}
+
+// Error describes the decision that was turned down
+func (e *DecisionError) Error() string {
+	if errors.Is(e.Err, ErrNegativeAmount) {
+		return fmt.Sprintf("%s %d: %v", e.Decision, e.Amount, e.Err)
+	}
+	return fmt.Sprintf("%s needs %d: %v, only %d available", e.Decision, e.Amount, e.Err, e.Available)
+}
+
+// Unwrap returns the reason the decision was turned down
+func (e *DecisionError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateDecisions checks a year's decisions in the order the questions are asked, each
+// one against what the corporation has left after the ones before it. It returns the
+// first problem found as a *DecisionError.
+func ValidateDecisions(s GameState, d Decisions) error {
+	if err := validateLoan(s, d.Borrow); err != nil {
+		return err
+	}
+	s.borrow(d.Borrow)
+
+	if err := validateConvert(s, d.BitcoinToSell); err != nil {
+		return err
+	}
+	s.convert(d.BitcoinToSell)
+
+	if err := validateGeneration(s, d.Generation); err != nil {
+		return err
+	}
+	s.choose(d.Generation)
+
+	if err := validateBuy(s, d.ComputersToBuy); err != nil {
+		return err
+	}
+	s.buy(d.ComputersToBuy)
+
+	if err := validateSell(s, d.ComputersToSell); err != nil {
+		return err
+	}
+	s.sell(d.ComputersToSell)
+
+	if err := validatePay(s, d.CashPaidToEmployees); err != nil {
+		return err
+	}
+	s.pay(d.CashPaidToEmployees)
+
+	return validateMaintenance(s, d.MaintenanceAmount)
+}
+
+// validateLoan checks that the bank will lend the corporation borrow bitcoins, or when borrow
+// is negative, that the corporation owes and has the cash to repay that much
+func validateLoan(s GameState, borrow int) error {
+	if borrow > s.Credit() {
+		return &DecisionError{Decision: DecisionLoan, Amount: borrow, Available: s.Credit(), Err: ErrOverCreditLimit}
+	}
+	if borrow < -s.Debt {
+		return &DecisionError{Decision: DecisionLoan, Amount: negate(borrow), Available: s.Debt, Err: ErrNotOwed}
+	}
+	if borrow < -s.Cash {
+		return &DecisionError{Decision: DecisionLoan, Amount: negate(borrow), Available: s.Cash, Err: ErrInsufficientCash}
+	}
+	return nil
+}
+
+// validateConvert checks that the corporation has the bitcoin to sell, or the dollars
+// to buy bitcoin when bitcoinToSell is negative
+func validateConvert(s GameState, bitcoinToSell int) error {
+	if bitcoinToSell > s.Cash {
+		return &DecisionError{Decision: DecisionConvert, Amount: bitcoinToSell, Available: s.Cash, Err: ErrInsufficientCash}
+	}
+	if bitcoinToSell < -(max(s.Fiat, 0) / s.ExchangeRate) {
+		return &DecisionError{Decision: DecisionConvert, Amount: costOf(negate(bitcoinToSell), s.ExchangeRate), Available: s.Fiat, Err: ErrInsufficientFiat}
+	}
+	return nil
+}
+
+// validateGeneration checks that the generation called name is on sale this year. An empty
+// name is the first generation, which is always on sale.
+func validateGeneration(s GameState, name string) error {
+	g, ok := s.generation(name)
+	if !ok || g.Since > s.Year {
+		return &DecisionError{Decision: DecisionGeneration, Available: len(s.Catalog()), Err: ErrNotOnSale}
+	}
+	return nil
+}
+
+// validateOffer checks that the player picked an offer in this year's catalog
+func validateOffer(s GameState, number int) error {
+	_, err := s.offer(number)
+	return err
+}
+
+// validateBuy checks that the corporation can pay for computersToBuy computers of the chosen generation
+func validateBuy(s GameState, computersToBuy int) error {
+	if computersToBuy < 0 {
+		return &DecisionError{Decision: DecisionBuy, Amount: computersToBuy, Err: ErrNegativeAmount}
+	}
+	g, _ := s.generation(s.buying)
+	if price := s.priceOf(g); price > 0 && computersToBuy > max(s.Cash, 0)/price {
+		return &DecisionError{Decision: DecisionBuy, Amount: costOf(computersToBuy, price), Available: s.Cash, Err: ErrInsufficientCash}
+	}
+	return nil
+}
+
+// validateSell checks that the corporation owns computersToSell computers
+func validateSell(s GameState, computersToSell int) error {
+	if computersToSell < 0 {
+		return &DecisionError{Decision: DecisionSell, Amount: computersToSell, Err: ErrNegativeAmount}
+	}
+	if computersToSell > s.Computers {
+		return &DecisionError{Decision: DecisionSell, Amount: computersToSell, Available: s.Computers, Err: ErrNotEnoughComputers}
+	}
+	return nil
+}
+
+// validatePay checks that the corporation has the cash to pay its employees cashPaidToEmployees
+func validatePay(s GameState, cashPaidToEmployees int) error {
+	if cashPaidToEmployees < 0 {
+		return &DecisionError{Decision: DecisionPay, Amount: cashPaidToEmployees, Err: ErrNegativeAmount}
+	}
+	if cashPaidToEmployees > s.Cash {
+		return &DecisionError{Decision: DecisionPay, Amount: cashPaidToEmployees, Available: s.Cash, Err: ErrInsufficientCash}
+	}
+	return nil
+}
+
+// validateMaintenance checks that maintenanceAmount is covered by the cash, and that there
+// are enough computers and staff to spend it on
+func validateMaintenance(s GameState, maintenanceAmount int) error {
+	if maintenanceAmount < 0 {
+		return &DecisionError{Decision: DecisionMaintain, Amount: maintenanceAmount, Err: ErrNegativeAmount}
+	}
+	if maintenanceAmount > s.Cash {
+		return &DecisionError{Decision: DecisionMaintain, Amount: maintenanceAmount, Available: s.Cash, Err: ErrInsufficientCash}
+	}
+	if maintenanceAmount > s.powerCost(s.Computers) {
+		return &DecisionError{Decision: DecisionMaintain, Amount: maintenanceAmount, Available: s.Computers, Err: ErrNotEnoughComputers}
+	}
+	if maintenanceAmount > s.powerCost(s.Rules.ComputersPerEmployee*s.Employees) {
+		return &DecisionError{Decision: DecisionMaintain, Amount: maintenanceAmount, Available: s.Employees, Err: ErrNotEnoughStaff}
+	}
+	return nil
+}
+
+// costOf returns what n things at price each cost, or math.MaxInt when that is more than an int
+// holds. The player can enter any int, so the checks above divide what the corporation has
+// instead of multiplying what the player entered, and only report the cost.
+func costOf(n, price int) int {
+	if price > 0 && n > math.MaxInt/price {
+		return math.MaxInt
+	}
+	return n * price
+}
+
+// negate returns -n, or math.MaxInt for math.MinInt, which has no int of the opposite sign
+func negate(n int) int {
+	if n == math.MinInt {
+		return math.MaxInt
+	}
+	return -n
+}
@@ -0,0 +1,236 @@
+package game
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// testState returns a corporation in its first year with 1000 bitcoins, 500 dollars at 100
+// dollars a bitcoin, and 200 standard computers at 20 bitcoins each, but only the staff to
+// maintain 100 of them. Its computers are worth 4000, so the bank lends it up to 2000.
+func testState() GameState {
+	s := NewGame(DefaultRules, NewRand(1))
+	s.Cash = 1000
+	s.Fiat = 500
+	s.ExchangeRate = 100
+	s.ComputerPrice = 20
+	s.Employees = 10
+	s.Computers = 200
+	s.Fleet = []Batch{{Generation: "standard", Count: 200}}
+	return s
+}
+
+// validationTest is a decision, and the error it should be turned down with or nil
+type validationTest struct {
+	name   string
+	state  func(s *GameState)
+	amount int
+	want   *DecisionError
+}
+
+// runValidationTests checks validate against every test, starting from testState
+func runValidationTests(t *testing.T, tests []validationTest, validate func(GameState, int) error) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testState()
+			if tt.state != nil {
+				tt.state(&s)
+			}
+			checkDecisionError(t, validate(s, tt.amount), tt.want)
+		})
+	}
+}
+
+// checkDecisionError checks that err is want, both field by field and by errors.Is against its reason
+func checkDecisionError(t *testing.T, err error, want *DecisionError) {
+	t.Helper()
+	if want == nil {
+		if err != nil {
+			t.Fatalf("got error %v, want none", err)
+		}
+		return
+	}
+
+	var de *DecisionError
+	if !errors.As(err, &de) {
+		t.Fatalf("got error %v, want a *DecisionError", err)
+	}
+	if *de != *want {
+		t.Errorf("got %+v, want %+v", *de, *want)
+	}
+	if !errors.Is(err, want.Err) {
+		t.Errorf("errors.Is(%v, %v) = false", err, want.Err)
+	}
+}
+
+func TestValidateLoan(t *testing.T) {
+	owing := func(s *GameState) { s.borrow(300) }
+	runValidationTests(t, []validationTest{
+		{name: "within credit", amount: 2000},
+		{name: "nothing", amount: 0},
+		{name: "over credit", amount: 2001,
+			want: &DecisionError{Decision: DecisionLoan, Amount: 2001, Available: 2000, Err: ErrOverCreditLimit}},
+		{name: "over credit left", state: owing, amount: 1701,
+			want: &DecisionError{Decision: DecisionLoan, Amount: 1701, Available: 1700, Err: ErrOverCreditLimit}},
+		{name: "repay", state: owing, amount: -300},
+		{name: "repay more than owed", state: owing, amount: -301,
+			want: &DecisionError{Decision: DecisionLoan, Amount: 301, Available: 300, Err: ErrNotOwed}},
+		{name: "repay nothing owed", amount: -1,
+			want: &DecisionError{Decision: DecisionLoan, Amount: 1, Available: 0, Err: ErrNotOwed}},
+		{name: "repay without the cash", state: func(s *GameState) { owing(s); s.Cash = 100 }, amount: -200,
+			want: &DecisionError{Decision: DecisionLoan, Amount: 200, Available: 100, Err: ErrInsufficientCash}},
+		{name: "repay the smallest int", state: owing, amount: math.MinInt,
+			want: &DecisionError{Decision: DecisionLoan, Amount: math.MaxInt, Available: 300, Err: ErrNotOwed}},
+	}, validateLoan)
+}
+
+func TestValidateConvert(t *testing.T) {
+	runValidationTests(t, []validationTest{
+		{name: "sell all bitcoin", amount: 1000},
+		{name: "buy with all dollars", amount: -5},
+		{name: "sell too much bitcoin", amount: 1001,
+			want: &DecisionError{Decision: DecisionConvert, Amount: 1001, Available: 1000, Err: ErrInsufficientCash}},
+		{name: "buy too much bitcoin", amount: -6,
+			want: &DecisionError{Decision: DecisionConvert, Amount: 600, Available: 500, Err: ErrInsufficientFiat}},
+		{name: "buy bitcoin costing more than an int", amount: -(math.MaxInt/100 + 1),
+			want: &DecisionError{Decision: DecisionConvert, Amount: math.MaxInt, Available: 500, Err: ErrInsufficientFiat}},
+		{name: "buy the smallest int of bitcoin", amount: math.MinInt,
+			want: &DecisionError{Decision: DecisionConvert, Amount: math.MaxInt, Available: 500, Err: ErrInsufficientFiat}},
+	}, validateConvert)
+}
+
+func TestValidateGeneration(t *testing.T) {
+	tests := []struct {
+		name       string
+		generation string
+		want       *DecisionError
+	}{
+		{name: "first generation", generation: ""},
+		{name: "on sale", generation: "refurbished"},
+		{name: "not on sale yet", generation: "pro",
+			want: &DecisionError{Decision: DecisionGeneration, Available: 2, Err: ErrNotOnSale}},
+		{name: "unknown", generation: "abacus",
+			want: &DecisionError{Decision: DecisionGeneration, Available: 2, Err: ErrNotOnSale}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkDecisionError(t, validateGeneration(testState(), tt.generation), tt.want)
+		})
+	}
+}
+
+func TestValidateOffer(t *testing.T) {
+	runValidationTests(t, []validationTest{
+		{name: "first", amount: 1},
+		{name: "last", amount: 2},
+		{name: "none", amount: 0,
+			want: &DecisionError{Decision: DecisionGeneration, Amount: 0, Available: 2, Err: ErrNotOnSale}},
+		{name: "past the catalog", amount: 3,
+			want: &DecisionError{Decision: DecisionGeneration, Amount: 3, Available: 2, Err: ErrNotOnSale}},
+	}, validateOffer)
+}
+
+func TestValidateBuy(t *testing.T) {
+	refurbished := func(s *GameState) { s.choose("refurbished") }
+	runValidationTests(t, []validationTest{
+		{name: "all the cash", amount: 50},
+		{name: "nothing", amount: 0},
+		{name: "negative", amount: -500,
+			want: &DecisionError{Decision: DecisionBuy, Amount: -500, Err: ErrNegativeAmount}},
+		{name: "too many", amount: 51,
+			want: &DecisionError{Decision: DecisionBuy, Amount: 1020, Available: 1000, Err: ErrInsufficientCash}},
+		{name: "costing more than an int", amount: math.MaxInt/20 + 1,
+			want: &DecisionError{Decision: DecisionBuy, Amount: math.MaxInt, Available: 1000, Err: ErrInsufficientCash}},
+		{name: "without cash", state: func(s *GameState) { s.Cash = -5 }, amount: 0},
+		{name: "cheaper generation", state: refurbished, amount: 100},
+		{name: "too many of a cheaper generation", state: refurbished, amount: 101,
+			want: &DecisionError{Decision: DecisionBuy, Amount: 1010, Available: 1000, Err: ErrInsufficientCash}},
+	}, validateBuy)
+}
+
+func TestValidateSell(t *testing.T) {
+	runValidationTests(t, []validationTest{
+		{name: "all", amount: 200},
+		{name: "negative", amount: -1,
+			want: &DecisionError{Decision: DecisionSell, Amount: -1, Err: ErrNegativeAmount}},
+		{name: "too many", amount: 201,
+			want: &DecisionError{Decision: DecisionSell, Amount: 201, Available: 200, Err: ErrNotEnoughComputers}},
+	}, validateSell)
+}
+
+func TestValidatePay(t *testing.T) {
+	runValidationTests(t, []validationTest{
+		{name: "all the cash", amount: 1000},
+		{name: "negative", amount: -1,
+			want: &DecisionError{Decision: DecisionPay, Amount: -1, Err: ErrNegativeAmount}},
+		{name: "too much", amount: 1001,
+			want: &DecisionError{Decision: DecisionPay, Amount: 1001, Available: 1000, Err: ErrInsufficientCash}},
+	}, validatePay)
+}
+
+func TestValidateMaintenance(t *testing.T) {
+	runValidationTests(t, []validationTest{
+		{name: "every computer the staff can maintain", amount: 200},
+		{name: "negative", amount: -1,
+			want: &DecisionError{Decision: DecisionMaintain, Amount: -1, Err: ErrNegativeAmount}},
+		{name: "more than the cash", state: func(s *GameState) { s.Cash = 100 }, amount: 101,
+			want: &DecisionError{Decision: DecisionMaintain, Amount: 101, Available: 100, Err: ErrInsufficientCash}},
+		{name: "more than the staff can maintain", amount: 201,
+			want: &DecisionError{Decision: DecisionMaintain, Amount: 201, Available: 10, Err: ErrNotEnoughStaff}},
+		{name: "more than the computers", amount: 401,
+			want: &DecisionError{Decision: DecisionMaintain, Amount: 401, Available: 200, Err: ErrNotEnoughComputers}},
+	}, validateMaintenance)
+}
+
+func TestValidateDecisions(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Decisions
+		want *DecisionError
+	}{
+		{name: "nothing", d: Decisions{}},
+		{name: "borrowed cash pays", d: Decisions{Borrow: 500, CashPaidToEmployees: 1500}},
+		{name: "sold computers pay", d: Decisions{ComputersToSell: 10, CashPaidToEmployees: 1200}},
+		{name: "bought computers leave less to pay", d: Decisions{ComputersToBuy: 40, CashPaidToEmployees: 201},
+			want: &DecisionError{Decision: DecisionPay, Amount: 201, Available: 200, Err: ErrInsufficientCash}},
+		{name: "payment leaves less to maintain", d: Decisions{CashPaidToEmployees: 900, MaintenanceAmount: 101},
+			want: &DecisionError{Decision: DecisionMaintain, Amount: 101, Available: 100, Err: ErrInsufficientCash}},
+		{name: "generation not on sale", d: Decisions{Generation: "asic", ComputersToBuy: 1},
+			want: &DecisionError{Decision: DecisionGeneration, Available: 2, Err: ErrNotOnSale}},
+		{name: "overflowing purchase", d: Decisions{ComputersToBuy: math.MaxInt/20 + 1},
+			want: &DecisionError{Decision: DecisionBuy, Amount: math.MaxInt, Available: 1000, Err: ErrInsufficientCash}},
+		{name: "overflowing conversion", d: Decisions{BitcoinToSell: -(math.MaxInt/100 + 1)},
+			want: &DecisionError{Decision: DecisionConvert, Amount: math.MaxInt, Available: 500, Err: ErrInsufficientFiat}},
+		{name: "first problem wins", d: Decisions{BitcoinToSell: 2000, ComputersToSell: -1},
+			want: &DecisionError{Decision: DecisionConvert, Amount: 2000, Available: 1000, Err: ErrInsufficientCash}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkDecisionError(t, ValidateDecisions(testState(), tt.d), tt.want)
+		})
+	}
+}
+
+// TestBuyComputersRetry is a regression test for the buy prompt ignoring the amount entered
+// after a refused one, which was lost to a variable shadowed inside the retry loop
+func TestBuyComputersRetry(t *testing.T) {
+	s := testState()
+	in := newConsole(nil, s.Rules, 1)
+	in.playback = []int{51, -5, 30}
+
+	bought, err := buyComputers(in, &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bought != 30 {
+		t.Errorf("bought %d computers, want the 30 entered last", bought)
+	}
+	if s.Computers != 230 || s.Cash != 400 {
+		t.Errorf("got %d computers and %d cash, want 230 and 400", s.Computers, s.Cash)
+	}
+	if len(in.playback) != 0 {
+		t.Errorf("%d answers left unread", len(in.playback))
+	}
+}
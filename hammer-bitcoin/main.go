@@ -1,18 +1,272 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
 	"mybitcoinapp/game"
+	"os"
+	"strings"
+	"time"
 )
 
+// sweepFlags collects every -sweep flag given on the command line
+type sweepFlags []game.Sweep
+
+func (s *sweepFlags) String() string {
+	var params []string
+	for _, sweep := range *s {
+		params = append(params, sweep.Param)
+	}
+	return strings.Join(params, ",")
+}
+
+func (s *sweepFlags) Set(value string) error {
+	sweep, err := game.ParseSweep(value)
+	if err != nil {
+		return err
+	}
+	*s = append(*s, sweep)
+	return nil
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "scores" {
+		listScores(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "achievements" {
+		listAchievements(os.Args[2:])
+		return
+	}
+
+	seed := flag.Uint64("seed", 0, "seed for the random number generator (0 picks one from the clock)")
+	replayFile := flag.String("replay", "", "play back a replay file written with -record")
+	recordFile := flag.String("record", "", "write a replay of the game to this file")
+	loadFile := flag.String("load", "", "resume a game saved with the save command")
+	tournament := flag.Int("tournament", 0, "play this many seeded games with each built-in strategy and print the results")
+	simulate := flag.Int("simulate", 0, "play this many seeded games with -strategy for every combination of -sweep values")
+	strategy := flag.String("strategy", "conservative", "strategy used by -simulate")
+	csvFile := flag.String("csv", "", "also write the -simulate results to this CSV file")
+	var sweeps sweepFlags
+	difficulty := flag.String("difficulty", "normal", "difficulty preset: "+strings.Join(game.PresetNames(), ", "))
+	rulesFile := flag.String("rules", "", "JSON or YAML rules file; rules it leaves out come from -difficulty")
+	playerName := flag.String("name", defaultPlayerName(), "name to put on the leaderboard")
+	scoresFile := flag.String("scores", game.DefaultScoresFile(), "leaderboard file (empty to keep no scores)")
+	profileFile := flag.String("profile", game.DefaultProfileFile(), "file the achievements you unlock are kept in (empty to keep none)")
+	serve := flag.String("serve", "", "host games over HTTP on this address, e.g. :8080, instead of playing")
+	idle := flag.Duration("idle", game.DefaultIdleTimeout, "how long -serve keeps a game nobody plays")
+	hotSeat := flag.String("hotseat", "", "play a match between these comma-separated players, taking turns at the keyboard")
+	host := flag.String("host", "", "host a match on this address, e.g. :7777, for -seats players to -join")
+	seats := flag.Int("seats", game.MinPlayers, "how many players a -host match waits for")
+	join := flag.String("join", "", "join the match hosted on this address as -name")
+	ledgerFile := flag.String("ledger", "", "export the year by year history of the game to this .csv or .json file")
+	lang := flag.String("lang", "", "language to play in: "+strings.Join(game.Locales(), ", ")+" (defaults to $LANG)")
+	inputFile := flag.String("input", "", "read the answers line by line from this file instead of the keyboard")
+	tui := flag.Bool("tui", false, "play on a full-screen dashboard instead of answering questions one by one")
+	flag.Var(&sweeps, "sweep", "rule to sweep with -simulate, as param=from:to:step or param=v1,v2 (repeatable)\nparams: "+strings.Join(game.RuleParams, ", ")+",\nor EVENT.chance, EVENT.min and EVENT.max for a declared event")
+	flag.Parse()
+
+	if _, err := game.SetLocale(*lang); err != nil {
+		if *lang != "" {
+			log.Fatal(err)
+		}
+		// an unsupported $LANG just means playing in English
+	}
+
+	if *inputFile != "" {
+		f, err := os.Open(*inputFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		game.SetInput(f)
+	}
+
+	rules, err := game.Preset(*difficulty)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *rulesFile != "" {
+		rules, err = game.LoadRules(*rulesFile, rules)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *tournament > 0 {
+		runTournament(rules, *tournament, *seed)
+		return
+	}
+
+	if *hotSeat != "" {
+		if err := game.PlayHotSeat(rules, *seed, strings.Split(*hotSeat, ",")); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *host != "" {
+		if err := game.HostMatch(*host, rules, *seed, *seats); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *join != "" {
+		if err := game.JoinMatch(*join, *playerName); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *serve != "" {
+		server := game.NewServer(rules, *idle)
+		fmt.Printf("Serving Bitcoin Miner at %s\n", *serve)
+		log.Fatal(server.ListenAndServe(*serve))
+	}
+
+	if *simulate > 0 {
+		runSimulation(rules, *simulate, *seed, *strategy, sweeps, *csvFile)
+		return
+	}
+
+	cfg := game.Config{
+		Rules:       rules,
+		Seed:        *seed,
+		RecordFile:  *recordFile,
+		PlayerName:  *playerName,
+		ScoresFile:  *scoresFile,
+		ProfileFile: *profileFile,
+		LedgerFile:  *ledgerFile,
+		TUI:         *tui,
+	}
+
+	if *replayFile != "" {
+		replay, err := game.LoadReplay(*replayFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg.Replay = replay
+	}
+
+	if *loadFile != "" {
+		if *replayFile != "" {
+			log.Fatal("-load and -replay cannot be used together")
+		}
+		saved, err := game.LoadGame(*loadFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg.Load = saved
+	}
+
 	playAgain := true
 
 	for playAgain {
-		game.Play()
-		playAgain = game.GetYesOrNo("Would you like to play again (y/n)?")
+		if err := game.Play(cfg); err != nil {
+			log.Fatal(err)
+		}
+		playAgain = game.GetYesOrNo(game.Text("playAgain"))
+
+		// only the first game uses the seed, replay or saved game we were given
+		cfg.Seed = 0
+		cfg.Replay = nil
+		cfg.Load = nil
 	}
 
 	fmt.Println("")
-	fmt.Println("Goodbye.")
+	fmt.Println(game.Text("goodbye"))
+}
+
+// defaultPlayerName returns the name of the logged in user, for the leaderboard
+func defaultPlayerName() string {
+	for _, env := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(env); name != "" {
+			return name
+		}
+	}
+	return "Anonymous"
+}
+
+// listScores is the scores subcommand: it prints the top of the leaderboard
+func listScores(args []string) {
+	scoresFlags := flag.NewFlagSet("scores", flag.ExitOnError)
+	scoresFile := scoresFlags.String("scores", game.DefaultScoresFile(), "leaderboard file")
+	difficulty := scoresFlags.String("difficulty", "", "only list scores on this difficulty preset")
+	top := scoresFlags.Int("top", 10, "how many scores to list (0 for all)")
+	scoresFlags.Parse(args)
+
+	// an unsupported $LANG just means listing them in English
+	_, _ = game.SetLocale("")
+
+	leaderboard, err := game.LoadLeaderboard(*scoresFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	game.PrintScores(os.Stdout, leaderboard.Top(*difficulty, *top))
+}
+
+// runTournament pits the built-in strategies against each other over the same seeded games
+func runTournament(rules game.Rules, games int, seed uint64) {
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+
+	fmt.Printf("Playing %d games per strategy on %s, seeds %d to %d.\n\n", games, rules.Name, seed, seed+uint64(games)-1)
+	results := game.RunTournament(game.Strategies(), rules, games, seed)
+	game.PrintTournament(os.Stdout, results)
+}
+
+// runSimulation plays a strategy across every combination of the swept rules and reports
+// how often each rating and each year of eviction came up
+func runSimulation(rules game.Rules, games int, seed uint64, strategy string, sweeps []game.Sweep, csvFile string) {
+	st, err := game.StrategyByName(strategy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+
+	fmt.Printf("Playing %d games per set of rules with the %s strategy, seeds %d to %d.\n\n", games, st.Name(), seed, seed+uint64(games)-1)
+	results, skipped, err := game.Simulate(st, rules, sweeps, games, seed)
+	for _, reason := range skipped {
+		log.Printf("skipped %v", reason)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("Skipped %d of %d sets of rules that are not valid.\n\n", len(skipped), len(skipped)+len(results))
+	}
+	game.PrintSimulation(os.Stdout, results)
+
+	if csvFile != "" {
+		f, err := os.Create(csvFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+
+		if err := game.WriteSimulationCSV(f, results); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// listAchievements is the achievements subcommand: it prints every achievement and whether it is unlocked
+func listAchievements(args []string) {
+	achievementsFlags := flag.NewFlagSet("achievements", flag.ExitOnError)
+	profileFile := achievementsFlags.String("profile", game.DefaultProfileFile(), "profile file")
+	achievementsFlags.Parse(args)
+
+	// an unsupported $LANG just means listing them in English
+	_, _ = game.SetLocale("")
+
+	profile, err := game.LoadProfile(*profileFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	game.PrintAchievements(os.Stdout, profile)
 }
@@ -0,0 +1,85 @@
+package doctor
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+// conversation is what a patient says in the tests, touching keywords, memories and the fallback
+var conversation = []string{
+	"Hello",
+	"I need a holiday.",
+	"My mother never listens to me",
+	"Why don't you understand?",
+	"I was smothered",
+	"I feel tired",
+	"Nothing else",
+}
+
+// TestConcurrentSessions talks to one Doctor and to the package's Eliza from many goroutines
+// at once, for as long as the script she talks from keeps changing. Run it with -race.
+func TestConcurrentSessions(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+
+	eliza := NewDoctor(scripts[DefaultLocale], 1)
+	changed := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			session := eliza.NewSession()
+			shared := NewSession()
+			for said := 0; ; said++ {
+				select {
+				case <-changed:
+					if want := min(said, maxHistory); len(session.History) != want {
+						t.Errorf("the session kept %d exchanges, want %d", len(session.History), want)
+					}
+					return
+				default:
+				}
+				input := conversation[said%len(conversation)]
+				if session.Respond(input) == "" || shared.Respond(input) == "" || Response(input) == "" {
+					t.Errorf("no answer to %q", input)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		SetLocale(Locales()[i%len(Locales())])
+		if Intro() == "" || ActiveScript() == nil {
+			t.Error("no script to talk from")
+		}
+	}
+	close(changed)
+	wg.Wait()
+}
+
+// TestSeed checks that Eliza has the same conversation again for the same seed
+func TestSeed(t *testing.T) {
+	talk := func(seed uint64) []string {
+		session := NewDoctor(scripts[DefaultLocale], seed).NewSession()
+		var answers []string
+		for range 3 {
+			for _, input := range conversation {
+				answers = append(answers, session.Respond(input))
+			}
+		}
+		return answers
+	}
+
+	first := talk(42)
+	if again := talk(42); !slices.Equal(first, again) {
+		t.Errorf("seed 42 answered\n%q\nthe first time and\n%q\nthe second", first, again)
+	}
+}
+
+func BenchmarkDoctorResponse(b *testing.B) {
+	eliza := NewDoctor(scripts[DefaultLocale], 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		eliza.Response(conversation[i%len(conversation)])
+	}
+}
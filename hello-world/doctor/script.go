@@ -0,0 +1,420 @@
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Script is everything Eliza says in one language, in the spirit of Weizenbaum's DOCTOR
+// script: the keywords she listens for, how she takes apart a sentence that holds one, and
+// how she puts her answer together from the pieces.
+type Script struct {
+	Locale   string    `yaml:"locale"`
+	Intro    string    `yaml:"intro"`
+	Keywords []Keyword `yaml:"keywords"`
+	// Fallback are the answers when no keyword is found, in which %1 is replaced by the whole input
+	Fallback []string `yaml:"fallback"`
+	// Reflections swap the words of the pieces put into an answer, such as "my" for "your"
+	Reflections map[string]string `yaml:"reflections"`
+	// Memory are the decompositions of the sentences a Session remembers, such as "* my *".
+	// The first that matches a sentence puts together an answer that is kept until no keyword
+	// is found in a later sentence, and given instead of a fallback answer.
+	Memory []Decomposition `yaml:"memory"`
+
+	// fallback takes the whole input as its one capture, and answers with Fallback
+	fallback Decomposition
+	// reflections are the Reflections by the lower-case word they swap
+	reflections map[string]string
+}
+
+// Keyword is a word or phrase Eliza listens for. When a sentence holds several keywords,
+// the one with the highest priority is answered, and of those the first in the script.
+type Keyword struct {
+	Keyword  string `yaml:"keyword"`
+	Priority int    `yaml:"priority"`
+	// Decompositions are tried in order until one matches the sentence
+	Decompositions []Decomposition `yaml:"decompositions"`
+
+	// line is where the keyword is declared in the script file
+	line int
+	// phrase is the keyword as it is compared with the input, a token at a time
+	phrase []string
+}
+
+// Decomposition takes a sentence apart. Its pattern is a line of words where every * stands
+// for any number of words, so "* i need *" takes "well, i need a holiday" apart into "well"
+// and "a holiday". Words only match whole words, so "i need" is not found in "hi needy".
+// Every reassembly is an answer in which %1 is replaced by the words the first * stood for,
+// %2 by the second, and so on.
+type Decomposition struct {
+	Pattern      string   `yaml:"pattern"`
+	Reassemblies []string `yaml:"reassemblies"`
+
+	// line is where the decomposition is declared in the script file, and reassemblyLines
+	// where each of its reassemblies is
+	line            int
+	reassemblyLines []int
+	// tokens is the pattern as it is compared with the input, a token at a time
+	tokens []string
+}
+
+// lineError is a problem with a script file, on the line it was found
+type lineError struct {
+	line int
+	msg  string
+}
+
+func (e *lineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.msg)
+}
+
+// errorAt returns a lineError for line
+func errorAt(line int, format string, args ...any) error {
+	return &lineError{line: line, msg: fmt.Sprintf(format, args...)}
+}
+
+// captureRef finds the %N references to captures in a reassembly
+var captureRef = regexp.MustCompile(`%(\d+)`)
+
+// tokenPattern matches the tokens of a sentence: words, which may hold apostrophes as in
+// "don't", and question marks. Letters are matched by their Unicode class, so that umlauts
+// and accents survive. Any other punctuation only separates words.
+const tokenPattern = `[\p{L}\p{N}]+(?:'[\p{L}\p{N}]+)*|\?`
+
+// tokenRE is tokenPattern, compiled
+var tokenRE = regexp.MustCompile(tokenPattern)
+
+// tokenize splits a sentence into lower-case tokens, which is how both the input and the
+// keywords are compared
+func tokenize(re *regexp.Regexp, s string) []string {
+	s = strings.ReplaceAll(strings.ToLower(s), "’", "'")
+	return re.FindAllString(s, -1)
+}
+
+// isWord reports whether a token is a word rather than punctuation
+func isWord(token string) bool {
+	return token != "?"
+}
+
+// LoadScript reads a script from a JSON or YAML file. Files ending in .json are read as
+// JSON, and any other file as YAML.
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading script: %w", err)
+	}
+	return ParseScript(path, data)
+}
+
+// ParseScript reads a script from data, and checks that every keyword, pattern and answer
+// in it can be used. Problems are reported with the name and the line they are on.
+func ParseScript(name string, data []byte) (*Script, error) {
+	s, err := parseScript(name, data)
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		// the decoder lists every value of the wrong type, which are reported on one line
+		return nil, fmt.Errorf("%s: %s", name, strings.Join(typeErr.Errors, "; "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	return s, nil
+}
+
+// parseScript reads and checks a script. JSON is a subset of YAML, so both are read by the
+// YAML decoder, which knows the line of everything it reads; JSON is checked for its
+// stricter syntax first.
+func parseScript(name string, data []byte) (*Script, error) {
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			var syntax *json.SyntaxError
+			if errors.As(err, &syntax) {
+				return nil, errorAt(1+bytes.Count(data[:syntax.Offset], []byte("\n")), "%v", err)
+			}
+			return nil, err
+		}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return nil, errors.New("the script is empty")
+	}
+	doc := root.Content[0]
+	if err := checkFields(doc, "locale", "intro", "keywords", "fallback", "reflections", "memory"); err != nil {
+		return nil, err
+	}
+
+	var s Script
+	if err := doc.Decode(&s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(doc); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// UnmarshalYAML reads a keyword and remembers the line it is on
+func (k *Keyword) UnmarshalYAML(node *yaml.Node) error {
+	if err := checkFields(node, "keyword", "priority", "decompositions"); err != nil {
+		return err
+	}
+	type plain Keyword
+	if err := node.Decode((*plain)(k)); err != nil {
+		return err
+	}
+	k.line = node.Line
+	return nil
+}
+
+// UnmarshalYAML reads a decomposition and remembers the lines it and its reassemblies are on
+func (d *Decomposition) UnmarshalYAML(node *yaml.Node) error {
+	if err := checkFields(node, "pattern", "reassemblies"); err != nil {
+		return err
+	}
+	type plain Decomposition
+	if err := node.Decode((*plain)(d)); err != nil {
+		return err
+	}
+	d.line = node.Line
+	if reassemblies := field(node, "reassemblies"); reassemblies != nil {
+		for _, r := range reassemblies.Content {
+			d.reassemblyLines = append(d.reassemblyLines, r.Line)
+		}
+	}
+	return nil
+}
+
+// checkFields checks that a mapping has no keys but fields
+func checkFields(node *yaml.Node, fields ...string) error {
+	if node.Kind != yaml.MappingNode {
+		return errorAt(node.Line, "expected a mapping")
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if !slices.Contains(fields, key.Value) {
+			return errorAt(key.Line, "unknown field %q, expected one of %s", key.Value, strings.Join(fields, ", "))
+		}
+	}
+	return nil
+}
+
+// field returns the value of the key called name in a mapping, or nil if there is none
+func field(node *yaml.Node, name string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == name {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// compile checks the script read from doc, and compiles the patterns of its decompositions
+func (s *Script) compile(doc *yaml.Node) error {
+	s.fallback = Decomposition{Pattern: "*", Reassemblies: s.Fallback, line: doc.Line}
+	if f := field(doc, "fallback"); f != nil {
+		s.fallback.line = f.Line
+		for _, r := range f.Content {
+			s.fallback.reassemblyLines = append(s.fallback.reassemblyLines, r.Line)
+		}
+	}
+	if len(s.Fallback) == 0 {
+		return errorAt(s.fallback.line, "the script needs at least one fallback answer")
+	}
+	if err := s.fallback.compile(); err != nil {
+		return err
+	}
+
+	for i := range s.Memory {
+		if err := s.Memory[i].compile(); err != nil {
+			return err
+		}
+	}
+
+	s.reflections = map[string]string{}
+	if f := field(doc, "reflections"); f != nil {
+		for i := 0; i+1 < len(f.Content); i += 2 {
+			key := f.Content[i]
+			word := strings.ToLower(key.Value)
+			if _, ok := s.reflections[word]; ok {
+				return errorAt(key.Line, "reflection %q is already declared", key.Value)
+			}
+			s.reflections[word] = f.Content[i+1].Value
+		}
+	}
+
+	declared := map[string]int{}
+	for i := range s.Keywords {
+		k := &s.Keywords[i]
+		k.phrase = tokenize(tokenRE, k.Keyword)
+		if len(k.phrase) == 0 {
+			return errorAt(k.line, "keyword %q has no words to listen for", k.Keyword)
+		}
+		phrase := strings.Join(k.phrase, " ")
+		if line, ok := declared[phrase]; ok {
+			return errorAt(k.line, "keyword %q is already declared on line %d", k.Keyword, line)
+		}
+		declared[phrase] = k.line
+		if len(k.Decompositions) == 0 {
+			return errorAt(k.line, "keyword %q needs at least one decomposition", k.Keyword)
+		}
+		for j := range k.Decompositions {
+			if err := k.Decompositions[j].compile(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compile checks a decomposition and splits its pattern into tokens
+func (d *Decomposition) compile() error {
+	captures := 0
+	for _, field := range strings.Fields(d.Pattern) {
+		if field == "*" {
+			d.tokens = append(d.tokens, field)
+			captures++
+			continue
+		}
+		tokens := tokenize(tokenRE, field)
+		if len(tokens) == 0 {
+			return errorAt(d.line, "pattern %q: %q has no words to match", d.Pattern, field)
+		}
+		d.tokens = append(d.tokens, tokens...)
+	}
+	if len(d.tokens) == 0 {
+		return errorAt(d.line, "the pattern is empty")
+	}
+
+	if len(d.Reassemblies) == 0 {
+		return errorAt(d.line, "pattern %q needs at least one reassembly", d.Pattern)
+	}
+	for i, r := range d.Reassemblies {
+		for _, ref := range captureRef.FindAllStringSubmatch(r, -1) {
+			n, _ := strconv.Atoi(ref[1])
+			if n < 1 || n > captures {
+				return errorAt(d.reassemblyLines[i], "reassembly %q uses %s, but pattern %q has %d captures", r, ref[0], d.Pattern, captures)
+			}
+		}
+	}
+	return nil
+}
+
+// match takes a tokenized sentence apart, and returns the tokens every * stood for.
+// It returns false if the sentence does not match the pattern.
+func (d *Decomposition) match(sentence []string) ([][]string, bool) {
+	return matchTokens(d.tokens, sentence)
+}
+
+// matchTokens matches the tokens of a pattern against those of a sentence. Every * but the
+// last takes as few tokens as it can, so the words after it are found at their first occurrence.
+func matchTokens(pattern, sentence []string) ([][]string, bool) {
+	m := matcher{
+		pattern:  pattern,
+		sentence: sentence,
+		failed:   make([]bool, (len(pattern)+1)*(len(sentence)+1)),
+	}
+	return m.match(0, 0)
+}
+
+// matcher matches a pattern against a sentence. It remembers where the rest of the pattern
+// could not be matched against the rest of the sentence, so that however many * the pattern
+// has, no such pair is tried twice.
+type matcher struct {
+	pattern, sentence []string
+	// failed is indexed by the pattern token times the sentence length plus one, plus the
+	// sentence token
+	failed []bool
+}
+
+// match matches the pattern from token p against the sentence from token s
+func (m *matcher) match(p, s int) ([][]string, bool) {
+	at := p*(len(m.sentence)+1) + s
+	if m.failed[at] {
+		return nil, false
+	}
+	captures, ok := m.matchRest(p, s)
+	if !ok {
+		m.failed[at] = true
+	}
+	return captures, ok
+}
+
+// matchRest is match without looking up what has failed before
+func (m *matcher) matchRest(p, s int) ([][]string, bool) {
+	if p == len(m.pattern) {
+		return nil, s == len(m.sentence)
+	}
+	if m.pattern[p] == "*" {
+		for end := s; end <= len(m.sentence); end++ {
+			if rest, ok := m.match(p+1, end); ok {
+				return append([][]string{m.sentence[s:end]}, rest...), true
+			}
+		}
+		return nil, false
+	}
+	if s == len(m.sentence) || m.sentence[s] != m.pattern[p] {
+		return nil, false
+	}
+	return m.match(p+1, s+1)
+}
+
+// reflect swaps the words of a capture by the script's reflections, and leaves out its punctuation
+func (s *Script) reflect(capture []string) string {
+	var words []string
+	for _, token := range capture {
+		if !isWord(token) {
+			continue
+		}
+		if reflection, ok := s.reflections[token]; ok {
+			token = reflection
+		}
+		words = append(words, token)
+	}
+	return strings.Join(words, " ")
+}
+
+// reassemble puts an answer together from a reassembly and the reflected captures
+func (s *Script) reassemble(reassembly string, captures [][]string) string {
+	return captureRef.ReplaceAllStringFunc(reassembly, func(ref string) string {
+		n, _ := strconv.Atoi(ref[1:])
+		return s.reflect(captures[n-1])
+	})
+}
+
+// byPriority returns the keywords found in a tokenized sentence, highest priority first,
+// and of the same priority in the order of the script
+func (s *Script) byPriority(sentence []string) []*Keyword {
+	var found []*Keyword
+	for i := range s.Keywords {
+		if containsPhrase(sentence, s.Keywords[i].phrase) {
+			found = append(found, &s.Keywords[i])
+		}
+	}
+	slices.SortStableFunc(found, func(a, b *Keyword) int { return b.Priority - a.Priority })
+	return found
+}
+
+// containsPhrase reports whether the tokens of phrase appear one after the other in sentence
+func containsPhrase(sentence, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(sentence); i++ {
+		if slices.Equal(sentence[i:i+len(phrase)], phrase) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package doctor
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchTokens(t *testing.T) {
+	tests := []struct {
+		pattern, sentence string
+		want              []string
+		ok                bool
+	}{
+		{pattern: "* i need *", sentence: "well i need a holiday", want: []string{"well", "a holiday"}, ok: true},
+		{pattern: "* i need *", sentence: "i need", want: []string{"", ""}, ok: true},
+		{pattern: "* i need *", sentence: "i want a holiday"},
+		{pattern: "* you * me", sentence: "you hate me and you love me", want: []string{"", "hate me and you love"}, ok: true},
+		{pattern: "*", sentence: "", want: []string{""}, ok: true},
+		{pattern: "a * b", sentence: "a b c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.sentence, func(t *testing.T) {
+			captures, ok := matchTokens(strings.Fields(tt.pattern), strings.Fields(tt.sentence))
+			if ok != tt.ok {
+				t.Fatalf("matched %v, want %v", ok, tt.ok)
+			}
+			var got []string
+			for _, c := range captures {
+				got = append(got, strings.Join(c, " "))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got captures %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMatchTokensManyWildcards is a regression test for matching taking exponential time in
+// the number of * when the sentence does not match
+func TestMatchTokensManyWildcards(t *testing.T) {
+	pattern := strings.Fields(strings.Repeat("* a ", 20) + "b")
+	sentence := strings.Fields(strings.Repeat("a ", 60))
+
+	start := time.Now()
+	if _, ok := matchTokens(pattern, sentence); ok {
+		t.Fatal("matched a sentence without a b")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v to give up", elapsed)
+	}
+}
+
+func TestReflect(t *testing.T) {
+	s, err := ParseScript("test.yaml", []byte(`
+fallback: ["%1"]
+reflections:
+  I: you
+  my: your
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.reflect(tokenize(tokenRE, "I lost My keys?")), "you lost your keys"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	_, err = ParseScript("test.yaml", []byte(`
+fallback: ["%1"]
+reflections:
+  my: your
+  My: Your
+`))
+	if err == nil || !strings.Contains(err.Error(), `line 5: reflection "My" is already declared`) {
+		t.Errorf("got error %v, want the duplicate reflection on line 5", err)
+	}
+}
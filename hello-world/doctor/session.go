@@ -0,0 +1,105 @@
+package doctor
+
+import "slices"
+
+// maxHistory is how many exchanges a session keeps in its history
+const maxHistory = 100
+
+// Exchange is one thing the patient said in a session, and Eliza's answer
+type Exchange struct {
+	Input    string
+	Response string
+}
+
+// Session is one conversation with Eliza. Unlike Response, it remembers what was said: the
+// topics the patient brought up, to come back to when there is nothing better to say, and
+// the answers already given, so that she goes through all the answers of a decomposition in
+// turn before she repeats one. A session is not safe for concurrent use, but sessions with
+// the same Doctor can go on side by side.
+type Session struct {
+	// History is what was said in the session, oldest first, up to the last maxHistory exchanges
+	History []Exchange
+
+	doctor *Doctor
+	// memories are the answers put together from the remembered topics, to be given in the
+	// order the topics came up
+	memories []string
+	// next is the reassembly every decomposition answers with next
+	next map[*Decomposition]int
+}
+
+// NewSession starts a conversation with Eliza, in the script she is currently talking from
+func NewSession() *Session {
+	return active.Load().NewSession()
+}
+
+// NewSession starts a conversation with the doctor
+func (d *Doctor) NewSession() *Session {
+	return &Session{
+		doctor: d,
+		next:   map[*Decomposition]int{},
+	}
+}
+
+// Respond answers what the patient said, and keeps both in the session's history, forgetting the
+// oldest exchange when the history is full
+func (s *Session) Respond(input string) string {
+	response := s.respond(tokenize(tokenRE, input))
+	if len(s.History) == maxHistory {
+		s.History = slices.Delete(s.History, 0, 1)
+	}
+	s.History = append(s.History, Exchange{Input: input, Response: response})
+	return response
+}
+
+// respond answers a tokenized sentence
+func (s *Session) respond(sentence []string) string {
+	s.remember(sentence)
+
+	// Try the keywords found in the sentence, the highest priority first. The first
+	// decomposition that takes the sentence apart gives the answer.
+	for _, keyword := range s.doctor.script.byPriority(sentence) {
+		for i := range keyword.Decompositions {
+			if answer, ok := s.answer(&keyword.Decompositions[i], sentence); ok {
+				return answer
+			}
+		}
+	}
+
+	// With no keyword to answer, come back to a topic from earlier, or else fall back.
+	if len(s.memories) > 0 {
+		answer := s.memories[0]
+		s.memories = s.memories[1:]
+		return answer
+	}
+	answer, _ := s.answer(&s.doctor.script.fallback, sentence)
+	return answer
+}
+
+// remember keeps an answer about the sentence for later, if one of the script's memory
+// decompositions takes it apart
+func (s *Session) remember(sentence []string) {
+	for i := range s.doctor.script.Memory {
+		if answer, ok := s.answer(&s.doctor.script.Memory[i], sentence); ok {
+			s.memories = append(s.memories, answer)
+			return
+		}
+	}
+}
+
+// answer takes the sentence apart with d, and puts the answer together from the
+// decomposition's next reassembly. It returns false if d does not match the sentence.
+func (s *Session) answer(d *Decomposition, sentence []string) (string, bool) {
+	captures, ok := d.match(sentence)
+	if !ok {
+		return "", false
+	}
+	// start every decomposition at a reassembly of its own, so that not every session
+	// begins with the same answers
+	next, ok := s.next[d]
+	if !ok {
+		next = s.doctor.intn(len(d.Reassemblies))
+	}
+	s.next[d] = (next + 1) % len(d.Reassemblies)
+	return s.doctor.script.reassemble(d.Reassemblies[next], captures), true
+}
@@ -0,0 +1,138 @@
+package doctor
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// possibleAnswers returns every answer Eliza may give to input in a new session, when the
+// keyword answers it, or the fallback when keyword is ""
+func possibleAnswers(t *testing.T, s *Script, keyword, input string) []string {
+	t.Helper()
+	decompositions := []Decomposition{s.fallback}
+	if keyword != "" {
+		i := slices.IndexFunc(s.Keywords, func(k Keyword) bool { return k.Keyword == keyword })
+		if i < 0 {
+			t.Fatalf("the %s script has no keyword %q", s.Locale, keyword)
+		}
+		decompositions = s.Keywords[i].Decompositions
+	}
+
+	sentence := tokenize(tokenRE, input)
+	for _, d := range decompositions {
+		if captures, ok := d.match(sentence); ok {
+			var answers []string
+			for _, r := range d.Reassemblies {
+				answers = append(answers, s.reassemble(r, captures))
+			}
+			return answers
+		}
+	}
+	t.Fatalf("keyword %q cannot answer %q", keyword, input)
+	return nil
+}
+
+// TestRespondKeywords is a corpus of sentences and the keyword of the shipped scripts that
+// should answer them, or "" for the fallback
+func TestRespondKeywords(t *testing.T) {
+	tests := []struct {
+		locale, input, keyword string
+	}{
+		// case and punctuation
+		{"en", "I NEED a holiday.", "i need"},
+		{"en", "well... i need, a holiday!", "i need"},
+		{"en", "Hi!", "hi"},
+		// apostrophes, straight and curly, are part of the word
+		{"en", "I'm tired", "i'm"},
+		{"en", "I’m tired", "i'm"},
+		{"en", "Im tired", ""},
+		{"en", "Why don't you listen", "why don't"},
+		{"en", "it's late", ""},
+		// keywords are whole words, never part of one
+		{"en", "I was smothered in the crowd", ""},
+		{"en", "This is a hit", ""},
+		{"en", "the children are loud", ""},
+		{"en", "Whatever", ""},
+		// question marks
+		{"en", "Really?", "?"},
+		{"en", "?", "?"},
+		{"en", "Do you dream?", "you"},
+		{"en", "What is the time?", "what"},
+		// the highest priority wins, whatever the order in the sentence or the script
+		{"en", "My mother hates me", "mother"},
+		{"en", "I need my father", "father"},
+		{"en", "Is it my computer?", "computer"},
+		{"en", "Why don't you like me?", "why don't"},
+		{"en", "I want to quit", "i want"},
+		{"de", "Meine Mutter hasst mich", "mutter"},
+		{"de", "ICH FÜHLE mich müde.", "ich fühle"},
+		{"de", "Ist das ein Computer?", "computer"},
+		{"de", "Hallo, wie geht es?", "wie"},
+		{"de", "Das Kindergeld ist weg", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale+"/"+tt.input, func(t *testing.T) {
+			script := scripts[tt.locale]
+			got := NewDoctor(script, 1).NewSession().Respond(tt.input)
+			if want := possibleAnswers(t, script, tt.keyword, tt.input); !slices.Contains(want, got) {
+				t.Errorf("got %q, want one of %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRespondPriority(t *testing.T) {
+	script, err := ParseScript("test.yaml", []byte(`
+fallback: ["fallback"]
+keywords:
+  - keyword: low
+    decompositions: [{pattern: "*", reassemblies: [low]}]
+  - keyword: first
+    priority: 5
+    decompositions: [{pattern: "*", reassemblies: [first]}]
+  - keyword: second
+    priority: 5
+    decompositions: [{pattern: "*", reassemblies: [second]}]
+  - keyword: high
+    priority: 10
+    decompositions:
+      - {pattern: "* never *", reassemblies: [never]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input, want string
+	}{
+		{"low", "low"},
+		{"low first", "first"},
+		// of the same priority, the keyword first in the script wins
+		{"second first low", "first"},
+		{"low second high never", "never"},
+		// a keyword that cannot take the sentence apart gives way to the next
+		{"high second low", "second"},
+		{"nothing", "fallback"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := NewDoctor(script, 1).NewSession().Respond(tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	session := NewDoctor(scripts[DefaultLocale], 1).NewSession()
+	for i := 0; i < maxHistory+5; i++ {
+		session.Respond(strconv.Itoa(i))
+	}
+	if len(session.History) != maxHistory {
+		t.Fatalf("the session kept %d exchanges, want %d", len(session.History), maxHistory)
+	}
+	if first, last := session.History[0].Input, session.History[maxHistory-1].Input; first != "5" || last != strconv.Itoa(maxHistory+4) {
+		t.Errorf("the session kept %q to %q, want the last %d exchanges", first, last, maxHistory)
+	}
+}
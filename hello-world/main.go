@@ -2,10 +2,13 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"log"
 	"myapp/doctor"
 	"os"
 	"strings"
+	"time"
 )
 
 func main() {
@@ -14,9 +17,38 @@ func main() {
 	// whatToSay := "Hello-World, again!"
 	// sayHelloWorld(whatToSay)
 
+	lang := flag.String("lang", "", "the language to talk in, such as de (default from LANG)")
+	scriptFile := flag.String("script", "", "talk from this JSON or YAML script instead of the default script of the language")
+	addr := flag.String("serve", "", "serve Eliza over HTTP on this address, such as :8080, instead of talking on the console")
+	idle := flag.Duration("idle", 30*time.Minute, "with -serve, how long a session may go quiet before it is forgotten")
+	seed := flag.Uint64("seed", 0, "seed Eliza's choice of answers, to have the same conversation again (default from the clock)")
+	flag.Parse()
+
+	if _, err := doctor.SetLocale(*lang); err != nil && *lang != "" {
+		log.Fatal(err)
+	}
+
+	if *scriptFile != "" {
+		script, err := doctor.LoadScript(*scriptFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		doctor.UseScript(script)
+	}
+
+	eliza := doctor.NewDoctor(doctor.ActiveScript(), *seed)
+
+	if *addr != "" {
+		if *idle <= 0 {
+			log.Fatal("-idle must be longer than 0")
+		}
+		log.Fatal(serve(*addr, eliza, *idle))
+	}
+
 	reader := bufio.NewReader(os.Stdin)
+	session := eliza.NewSession()
 
-	whatToSay := doctor.Intro()
+	whatToSay := eliza.Intro()
 	// sayHelloWorld(whatToSay)
 	fmt.Println(whatToSay)
 
@@ -32,7 +64,7 @@ func main() {
 		if userInput == "quit" {
 			break
 		} else {
-			fmt.Println(doctor.Response(userInput))
+			fmt.Println(session.Respond(userInput))
 		}
 
 	}
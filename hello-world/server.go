@@ -0,0 +1,230 @@
+package main
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"myapp/doctor"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxMessageSize is the longest message a patient can send, in bytes
+const maxMessageSize = 4096
+
+// maxSessions is how many sessions the server holds at once; more are turned away until
+// some expire
+const maxSessions = 10000
+
+// requestTimeout is how long a patient has to send a request, and the server to answer it or
+// a message on a WebSocket
+const requestTimeout = 10 * time.Second
+
+// keepAlive is how long a connection is kept open for the next request
+const keepAlive = time.Minute
+
+// page is the chat page served at /, for trying Eliza in a browser
+//
+//go:embed web/index.html
+var page []byte
+
+// chatServer talks to patients over HTTP, every one of them in a session of their own.
+// A session that goes quiet for longer than idle is forgotten.
+type chatServer struct {
+	doctor *doctor.Doctor
+	idle   time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*chatSession
+}
+
+// chatSession is a conversation held over HTTP, and when the patient last said something
+type chatSession struct {
+	mu       sync.Mutex
+	session  *doctor.Session
+	lastSeen time.Time
+}
+
+// newChatServer returns a server for Eliza talking as doc, which forgets sessions idle for longer than idle
+func newChatServer(doc *doctor.Doctor, idle time.Duration) *chatServer {
+	return &chatServer{
+		doctor:   doc,
+		idle:     idle,
+		sessions: map[string]*chatSession{},
+	}
+}
+
+// serve listens on addr until the server fails
+func serve(addr string, doc *doctor.Doctor, idle time.Duration) error {
+	srv := newChatServer(doc, idle)
+	go srv.expire()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", servePage)
+	mux.HandleFunc("POST /sessions", srv.createSession)
+	mux.HandleFunc("POST /sessions/{id}/messages", srv.postMessage)
+	mux.HandleFunc("GET /sessions/{id}/ws", srv.chat)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: requestTimeout,
+		ReadTimeout:       requestTimeout,
+		WriteTimeout:      requestTimeout,
+		IdleTimeout:       keepAlive,
+	}
+	log.Printf("Eliza is listening on %s", addr)
+	return server.ListenAndServe()
+}
+
+// servePage answers with the chat page
+func servePage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write(page); err != nil {
+		log.Println(err)
+	}
+}
+
+// createSession starts a session, and answers with its id and Eliza's intro
+func (srv *chatServer) createSession(w http.ResponseWriter, r *http.Request) {
+	id, err := newSessionID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	srv.mu.Lock()
+	full := len(srv.sessions) >= maxSessions
+	if !full {
+		srv.sessions[id] = &chatSession{session: srv.doctor.NewSession(), lastSeen: time.Now()}
+	}
+	srv.mu.Unlock()
+	if full {
+		http.Error(w, "Eliza is talking to too many patients, try again later", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Location", "/sessions/"+id)
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id, "intro": srv.doctor.Intro()})
+}
+
+// postMessage answers a message sent to a session as {"message": "..."}
+func (srv *chatServer) postMessage(w http.ResponseWriter, r *http.Request) {
+	cs, ok := srv.session(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "no such session", http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxMessageSize)).Decode(&body); err != nil {
+		http.Error(w, "expected a JSON body like {\"message\": \"...\"}", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"response": cs.respond(body.Message)})
+}
+
+// upgrader turns a request into a WebSocket connection
+var upgrader = websocket.Upgrader{}
+
+// chat talks to a session over a WebSocket: every text message is answered with one. The
+// connection is closed when the session has been idle for too long. The upgrade lifts the
+// server's timeouts, so the connection keeps deadlines of its own.
+func (srv *chatServer) chat(w http.ResponseWriter, r *http.Request) {
+	cs, ok := srv.session(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "no such session", http.StatusNotFound)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already answered the request
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxMessageSize)
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(srv.idle))
+		kind, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Println(err)
+			}
+			return
+		}
+		if kind != websocket.TextMessage {
+			continue
+		}
+		conn.SetWriteDeadline(time.Now().Add(requestTimeout))
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(cs.respond(string(message)))); err != nil {
+			log.Println(err)
+			return
+		}
+	}
+}
+
+// session returns the session called id, unless there is none or it has expired
+func (srv *chatServer) session(id string) (*chatSession, bool) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	cs, ok := srv.sessions[id]
+	return cs, ok
+}
+
+// expire forgets the sessions that have been idle for too long, checking every so often for ever
+func (srv *chatServer) expire() {
+	ticker := time.NewTicker(max(srv.idle/2, time.Second))
+	defer ticker.Stop()
+	for now := range ticker.C {
+		srv.mu.Lock()
+		for id, cs := range srv.sessions {
+			if cs.idleSince(now) > srv.idle {
+				delete(srv.sessions, id)
+			}
+		}
+		srv.mu.Unlock()
+	}
+}
+
+// respond answers a message in the session, one message at a time
+func (cs *chatSession) respond(message string) string {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.lastSeen = time.Now()
+	return cs.session.Respond(message)
+}
+
+// idleSince returns how long the session has been idle at now
+func (cs *chatSession) idleSince(now time.Time) time.Duration {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return now.Sub(cs.lastSeen)
+}
+
+// newSessionID returns a random id that cannot be guessed from the others
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeJSON answers with v as JSON and the status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(err)
+	}
+}